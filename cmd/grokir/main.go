@@ -1,13 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"grokir/internal/cli/command"
 	_ "grokir/internal/cli/commands"
+	"grokir/internal/cli/formatter"
+	"grokir/internal/config"
 	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
+	"grokir/internal/pager"
+	"grokir/internal/term"
 )
 
 var (
@@ -15,68 +28,447 @@ var (
 	date    = "unknown"
 )
 
+// Process exit codes.
+const (
+	exitFailure     = 1
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitNetwork     = 4
+	exitRateLimited = 5
+	exitTimeout     = 6
+	exitInterrupted = 130
+)
+
 func usage() {
 	fmt.Fprintf(os.Stderr, `grokir - CLI for Grokipedia
 Version: %s
 Build date: %s
 
 Usage:
-  grokir search <query> [-l <num>] [-o <num>] [--json]
-  grokir page <slug> [--json]
+  grokir [-f|--format <format>] [--json] [--json-envelope] [--summary]
+         [--fields <list>] [--jq <query>]
+         [--template <text>|--template-file <file>]
+         [--timeout <duration>] [--retries <num>] [--rps <num>] [--no-cache] [--offline]
+         [--no-pager] <command> [args]
+  grokir search <query> [-l <num>] [-o <num>] [--all] [--max <num>]
+  grokir page [-f <file>] [-c <num>] <slug>...
+  grokir page [--toc] [--section <heading>] <slug>
+  grokir links [--validate] [-c <num>] <slug>
+  grokir export [--format md|html|epub] [--out <path>] [--title <title>] [-q <query>] [--max <num>]
+                [-f <file>] [-c <num>] <slug>...
+  grokir cache <stats|clear|prune>
+  grokir config <get <key>|set <key> <value>|list|path>
+  grokir formats
   grokir version
 
 Commands:
   search   Search articles on Grokipedia
-  page     Show a page by slug
+  page     Show one or more pages by slug
+  links    List the links and citations of a page
+  export   Write pages to files
+  cache    Inspect or empty the local response cache
+  config   Show or change settings in the config file
+  formats  List output formats and the commands that support them
   version  Show version and build date
 
 Options:
-  -l       Maximum number of results (default: 10)
-  -o       Offset for pagination (default: 0)
-  --json   JSON output
+  -l         Maximum number of results (default: 10)
+  -o         Offset for pagination (default: 0)
+  --all      Fetch every search result page (-l sets the page size)
+  --max      Fetch search result pages until this many results (search; export: with -q, default: 10)
+  -f         Read page slugs from a file, one per line (- for stdin; after page or export)
+  -c         Pages fetched in parallel (default: 4)
+  --toc      List the sections of a page
+  --section  Show only the page section whose heading matches
+  --validate Check that the pages a page links to exist (links)
+  --format   Export file format: md, html or epub (after export)
+  --out      Directory exported files are written to, or the EPUB file (export; default: .)
+  --title    EPUB book title (export)
+  -q         Export the top results of a search (export)
+  -f, --format
+             Output format, e.g. text, json, yaml, markdown or html (default: text;
+             see "grokir formats"; before the command)
+  --json     JSON output (same as --format json)
+  --json-envelope
+             Wrap JSON output in an object describing the request
+             (schema_version, command, query, limit, offset, count, results,
+             fetched_at; implies --format json)
+  --summary  End streamed (ndjson) output with a summary record
+  --fields   Comma-separated result fields to output, e.g. slug,title
+             (csv, tsv, json, ndjson, yaml)
+  --jq       Print the values a jq-style query selects from JSON output,
+             e.g. '.[].slug' (json, ndjson; implies --format json)
+  --template Render each search result or page with a Go template,
+             e.g. '{{.Title}} ({{.Slug}})'
+  --template-file
+             Read the template from a file
+  --timeout  Abort if the command takes longer than this (e.g. 30s; default: none)
+  --retries  Retries for failed requests (default: 2)
+  --retry-max-wait
+             Longest wait between retries, including Retry-After (default: 10s)
+  --rps      Maximum requests per second sent to Grokipedia (default: 5; 0 for no limit)
+  --burst    Requests allowed in a burst above --rps (default: 5)
+  --no-cache Always fetch from Grokipedia, bypassing the local cache
+  --cache-ttl
+             How long cached responses are reused (default: 24h)
+  --offline  Serve pages and searches only from the local cache
+  --no-pager Print long output directly instead of through $PAGER
+
+Defaults for these options can be set in the config file (see
+"grokir config path") or with GROKIR_* environment variables (see
+"grokir config list").
 `, version, date)
 }
 
+// settings are the values resolved from the environment, the config
+// file and built-in defaults. They become the defaults of the global
+// flags, so that flags take precedence over everything else.
+type settings struct {
+	baseURL      string
+	userAgent    string
+	format       string
+	searchLimit  int
+	timeout      time.Duration
+	retries      int
+	retryMaxWait time.Duration
+	rps          float64
+	burst        int
+	cache        bool
+	cacheTTL     time.Duration
+	cacheDir     string
+	offline      bool
+	proxy        string
+	pager        bool
+	pagerCommand string
+}
+
+// loadSettings resolves the settings. Invalid values are replaced by
+// their defaults and reported in the returned error.
+func loadSettings(cfg *config.Config) (settings, error) {
+	var s settings
+	var errs []error
+	s.baseURL = setting(&errs, cfg.String, config.BaseURL)
+	s.userAgent = setting(&errs, cfg.String, config.UserAgent)
+	s.format = setting(&errs, cfg.String, config.Format)
+	s.searchLimit = setting(&errs, cfg.Int, config.SearchLimit)
+	s.timeout = setting(&errs, cfg.Duration, config.Timeout)
+	s.retries = setting(&errs, cfg.Int, config.Retries)
+	s.retryMaxWait = setting(&errs, cfg.Duration, config.RetryMaxWait)
+	s.rps = setting(&errs, cfg.Float, config.RPS)
+	s.burst = setting(&errs, cfg.Int, config.Burst)
+	s.cache = setting(&errs, cfg.Bool, config.Cache)
+	s.cacheTTL = setting(&errs, cfg.Duration, config.CacheTTL)
+	s.cacheDir = setting(&errs, cfg.String, config.CacheDir)
+	s.offline = setting(&errs, cfg.Bool, config.Offline)
+	s.proxy = setting(&errs, cfg.String, config.Proxy)
+	s.pager = setting(&errs, cfg.Bool, config.Pager)
+	s.pagerCommand = setting(&errs, cfg.String, config.PagerCommand)
+	return s, errors.Join(errs...)
+}
+
+// setting resolves the setting name with get, adding its error, if any,
+// to errs.
+func setting[T any](errs *[]error, get func(string) (T, error), name string) T {
+	v, err := get(name)
+	if err != nil {
+		*errs = append(*errs, err)
+	}
+	return v
+}
+
 func main() {
 	flag.Usage = usage
 
-	jsonOutput := flag.Bool("json", false, "JSON output")
+	cfgPath, err := config.DefaultPath()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(exitFailure)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(exitFailure)
+	}
+	// Invalid settings are reported once the command is known: config
+	// must still run so they can be fixed, and version needs none.
+	s, settingsErr := loadSettings(cfg)
+
+	format := flag.String("format", s.format, "output format (see grokir formats)")
+	flag.StringVar(format, "f", s.format, "output format (same as --format)")
+	jsonOutput := flag.Bool("json", false, "JSON output (same as --format json)")
+	envelope := flag.Bool("json-envelope", false, "wrap JSON output in an object describing the request")
+	summary := flag.Bool("summary", false, "end streamed output with a summary record")
+	fields := flag.String("fields", "", "comma-separated fields to output")
+	jq := flag.String("jq", "", "jq-style query applied to JSON output")
+	tmpl := flag.String("template", "", "render each result with a Go template")
+	tmplFile := flag.String("template-file", "", "read the --template from a file")
+	timeout := flag.Duration("timeout", s.timeout, "overall command timeout")
+	retries := flag.Int("retries", s.retries, "retries for failed requests")
+	retryMaxWait := flag.Duration("retry-max-wait", s.retryMaxWait, "longest wait between retries")
+	rps := flag.Float64("rps", s.rps, "maximum requests per second")
+	burst := flag.Int("burst", s.burst, "requests allowed in a burst")
+	noCache := flag.Bool("no-cache", !s.cache, "bypass the local response cache")
+	cacheTTL := flag.Duration("cache-ttl", s.cacheTTL, "how long cached responses are reused")
+	offline := flag.Bool("offline", s.offline, "serve requests only from the local cache")
+	noPager := flag.Bool("no-pager", !s.pager, "do not page long output")
 
 	flag.Parse()
 
 	if flag.NArg() < 1 {
 		usage()
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	cmd := flag.Arg(0)
 	args := flag.Args()[1:]
+	// Until the output format is settled below, errors are JSON objects
+	// whenever JSON output was asked for.
+	jsonErrors := *jsonOutput || *envelope || *jq != ""
+	if f, ok := formatter.Lookup(*format); ok && f.JSON {
+		jsonErrors = true
+	}
+	if settingsErr != nil && cmd != "config" && cmd != "version" {
+		os.Exit(fail(context.Background(), settingsErr, *timeout, jsonErrors))
+	}
+
+	// --json and the template options choose the format themselves, so
+	// an explicit --format naming another one is a mistake.
+	var explicitFormat string
+	flag.Visit(func(fl *flag.Flag) {
+		if fl.Name == "format" || fl.Name == "f" {
+			explicitFormat = *format
+		}
+	})
+	tmplOption := "--template"
+	if *tmplFile != "" {
+		tmplOption = "--template-file"
+	}
+	switch {
+	case *jsonOutput && (*tmpl != "" || *tmplFile != ""):
+		failUsage(jsonErrors, fmt.Sprintf("--json cannot be used with %s", tmplOption))
+	case *jsonOutput && explicitFormat != "" && explicitFormat != string(command.OutputJSON):
+		failUsage(jsonErrors, fmt.Sprintf("--json cannot be used with --format %s", explicitFormat))
+	case (*tmpl != "" || *tmplFile != "") && explicitFormat != "" && explicitFormat != string(command.OutputTemplate):
+		failUsage(jsonErrors, fmt.Sprintf("%s cannot be used with --format %s", tmplOption, explicitFormat))
+	}
+
+	outputMode := command.OutputMode(*format)
+	if *jsonOutput {
+		outputMode = command.OutputJSON
+	}
+	if *tmplFile != "" {
+		if *tmpl != "" {
+			failUsage(jsonErrors, "--template and --template-file cannot be used together")
+		}
+		data, err := os.ReadFile(*tmplFile)
+		if err != nil {
+			failUsage(jsonErrors, err.Error())
+		}
+		*tmpl = string(data)
+	}
+	if *tmpl != "" {
+		if _, err := formatter.ParseTemplate(*tmpl); err != nil {
+			failUsage(jsonErrors, err.Error())
+		}
+		outputMode = command.OutputTemplate
+	} else if outputMode == command.OutputTemplate {
+		failUsage(jsonErrors, "--format template requires --template or --template-file")
+	}
+	if (*jq != "" || *envelope) && outputMode == command.OutputText {
+		outputMode = command.OutputJSON
+	}
+	f, ok := formatter.Lookup(string(outputMode))
+	if !ok {
+		failUsage(jsonErrors, fmt.Sprintf("unknown output format %q (see grokir formats)", outputMode))
+	}
+	jsonErrors = f.JSON
+	if *jq != "" {
+		if _, err := formatter.ParseQuery(*jq); err != nil {
+			failUsage(jsonErrors, err.Error())
+		}
+	}
+	if *envelope && outputMode != command.OutputJSON {
+		failUsage(jsonErrors, fmt.Sprintf("--json-envelope cannot be used with --format %s", outputMode))
+	}
+	if *fields != "" && f.Fields == nil {
+		failUsage(jsonErrors, fmt.Sprintf("--format %s does not support --fields", f.Name))
+	}
+	if *jq != "" && !f.Query {
+		failUsage(jsonErrors, fmt.Sprintf("--format %s does not support --jq", f.Name))
+	}
+	if c, ok := command.Get(cmd); ok {
+		if err := formatter.CheckCommand(c, outputMode); err != nil {
+			failUsage(jsonErrors, err.Error())
+		}
+		if err := formatter.CheckFields(c, f, splitFields(*fields)); err != nil {
+			failUsage(jsonErrors, err.Error())
+		}
+		// Commands that print their own output cannot honor the
+		// options that shape formatted results.
+		if _, ok := c.(command.FormattedCommand); !ok {
+			for _, o := range []struct {
+				name string
+				set  bool
+			}{
+				{"--fields", *fields != ""},
+				{"--jq", *jq != ""},
+				{"--json-envelope", *envelope},
+				{"--summary", *summary},
+				{"--template", *tmpl != ""},
+			} {
+				if o.set {
+					failUsage(jsonErrors, fmt.Sprintf("%s does not support %s", cmd, o.name))
+				}
+			}
+		}
+	}
 
 	if cmd == "version" {
 		fmt.Printf("Version: %s\nBuild date: %s\n", version, date)
 		return
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
 	client := grokipedia.NewClient()
+	client.BaseURL = s.baseURL
+	client.UserAgent = s.userAgent
+	client.Retry.MaxAttempts = *retries + 1
+	client.Retry.MaxDelay = *retryMaxWait
 
-	var outputMode command.OutputMode
-	if *jsonOutput {
-		outputMode = command.OutputJSON
-	} else {
-		outputMode = command.OutputText
+	var cache *httpcache.Cache
+	cacheDir := s.cacheDir
+	if cacheDir == "" {
+		cacheDir, _ = httpcache.DefaultDir()
+	}
+	if cacheDir != "" {
+		cache = httpcache.New(cacheDir, *cacheTTL)
+	}
+	if *offline && (cache == nil || *noCache) {
+		failUsage(jsonErrors, "--offline requires the local cache")
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if s.proxy != "" {
+		proxyURL, _ := url.Parse(s.proxy)
+		base.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	// The limiter sits below the cache so that cache hits are not
+	// throttled.
+	transport := grokipedia.NewRateLimiter(*rps, *burst, nil).Transport(base)
+	if cache != nil && !*noCache {
+		cached := cache.Transport(transport)
+		cached.Offline = *offline
+		cached.Storable = grokipedia.CacheableResponse
+		transport = cached
+	}
+	client.HTTP = &http.Client{Transport: transport}
+	if *offline {
+		client.Retry.MaxAttempts = 1
+	}
+
+	var width, height int
+	var pagerCommand string
+	if term.IsTerminal(os.Stdout) {
+		width, height = term.Size(os.Stdout)
+		if !*noPager {
+			pagerCommand = s.pagerCommand
+			if pagerCommand == "" {
+				pagerCommand = pager.Command()
+			}
+		}
 	}
 
 	rt := command.Runtime{
-		Client: client,
-		Output: outputMode,
+		Context:     ctx,
+		Client:      client,
+		Output:      outputMode,
+		Cache:       cache,
+		Offline:     *offline,
+		Config:      cfg,
+		SearchLimit: s.searchLimit,
+		Color:       term.Color(os.Stdout),
+		Width:       width,
+		Height:      height,
+		Pager:       pagerCommand,
+		Summary:     *summary,
+		Fields:      splitFields(*fields),
+		Template:    *tmpl,
+		Query:       *jq,
+		Envelope:    *envelope,
 	}
 
-	err := command.Run(rt, cmd, args)
+	err = command.Run(rt, cmd, args)
 	if err != nil {
+		os.Exit(fail(ctx, err, *timeout, jsonErrors))
+	}
+}
+
+// splitFields splits a comma-separated --fields value, dropping empty
+// names.
+func splitFields(s string) []string {
+	var fields []string
+	for f := range strings.SplitSeq(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// exitCodes maps error codes to process exit codes. Other errors exit
+// with exitFailure.
+var exitCodes = map[string]int{
+	formatter.CodeUsage:       exitUsage,
+	formatter.CodeNotFound:    exitNotFound,
+	formatter.CodeNotCached:   exitNotFound,
+	formatter.CodeRateLimited: exitRateLimited,
+	formatter.CodeUnavailable: exitNetwork,
+	formatter.CodeTimeout:     exitTimeout,
+	formatter.CodeInterrupted: exitInterrupted,
+}
+
+// fail reports err on stderr, as a JSON object when jsonErrors is set,
+// and returns the process exit code for it. An error caused by ctx
+// ending is reported as a timeout or interruption.
+func fail(ctx context.Context, err error, timeout time.Duration, jsonErrors bool) int {
+	switch {
+	case ctx.Err() == nil || !errors.Is(err, ctx.Err()):
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = &command.Error{Message: fmt.Sprintf("timed out after %s", timeout), Err: ctx.Err()}
+	case errors.Is(ctx.Err(), context.Canceled):
+		err = &command.Error{Message: "interrupted", Err: ctx.Err()}
+	}
+
+	code := formatter.ErrorCode(err)
+	if jsonErrors {
+		fmt.Fprint(os.Stderr, formatter.FormatErrorJSON(err, ""))
+	} else {
 		fmt.Fprintln(os.Stderr, err.Error())
-		if cmdErr, ok := err.(*command.Error); ok && cmdErr.IsUsage() {
+		if code == formatter.CodeUsage {
 			usage()
 		}
-		os.Exit(1)
 	}
+	if exit, ok := exitCodes[code]; ok {
+		return exit
+	}
+	return exitFailure
+}
+
+// failUsage reports a usage error found before the command runs, as a
+// JSON object when jsonErrors is set, and exits.
+func failUsage(jsonErrors bool, msg string) {
+	if jsonErrors {
+		fmt.Fprint(os.Stderr, formatter.FormatErrorJSON(command.NewUsageError(msg), ""))
+	} else {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+	os.Exit(exitUsage)
 }
@@ -6,3 +6,19 @@ type Command interface {
 	Usage() string
 	Run(ctx Runtime, args []string) error
 }
+
+// FormattedCommand is implemented by commands that print their results
+// through the formatter selected with --format. Results returns the
+// kinds of results a format must handle to be used with the command.
+type FormattedCommand interface {
+	Command
+	Results() []ResultKind
+}
+
+// OutputCommand is implemented by commands that print their own output
+// rather than through a formatter. Outputs returns the output formats
+// the command honors; no others can be used with it.
+type OutputCommand interface {
+	Command
+	Outputs() []OutputMode
+}
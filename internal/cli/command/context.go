@@ -1,17 +1,53 @@
 package command
 
 import (
+	"context"
+
+	"grokir/internal/config"
 	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
 )
 
-// OutputMode represents the output format for command results.
+// OutputMode represents the output format for command results. It is
+// the name of a format registered with the formatter package.
 type OutputMode string
 
 const (
 	OutputText OutputMode = "text"
 	OutputJSON OutputMode = "json"
+	OutputHTML OutputMode = "html"
+	// OutputTemplate renders results with Runtime.Template.
+	OutputTemplate OutputMode = "template"
+)
+
+// ResultKind names a kind of result that commands print through a
+// formatter. Each kind has a formatter interface below.
+type ResultKind string
+
+const (
+	ResultSearch ResultKind = "search"
+	ResultPage   ResultKind = "page"
+	ResultTOC    ResultKind = "toc"
+	ResultLinks  ResultKind = "links"
 )
 
+// FormattedBy reports whether f implements the formatter interface for
+// results of kind k.
+func (k ResultKind) FormattedBy(f any) bool {
+	var ok bool
+	switch k {
+	case ResultSearch:
+		_, ok = f.(SearchFormatter)
+	case ResultPage:
+		_, ok = f.(PageFormatter)
+	case ResultTOC:
+		_, ok = f.(TOCFormatter)
+	case ResultLinks:
+		_, ok = f.(LinksFormatter)
+	}
+	return ok
+}
+
 // SearchFormatter defines the interface for formatting search results.
 type SearchFormatter interface {
 	FormatSearch([]grokipedia.SearchResult) (string, error)
@@ -23,8 +59,98 @@ type PageFormatter interface {
 	FormatPage(*grokipedia.Page) (string, error)
 }
 
+// PagesFormatter is implemented by page formatters that render several
+// pages as a single document, such as a JSON array.
+type PagesFormatter interface {
+	FormatPages([]*grokipedia.Page) (string, error)
+}
+
+// TOCFormatter is implemented by page formatters that can render a
+// page's table of contents.
+type TOCFormatter interface {
+	FormatTOC(*grokipedia.Page, []grokipedia.Section) (string, error)
+}
+
+// LinksFormatter defines the interface for formatting the links of a
+// page.
+type LinksFormatter interface {
+	FormatLinks(grokipedia.PageLinks) (string, error)
+}
+
+// StreamFormatter is implemented by formatters whose output for several
+// results is the output for each in turn, such as NDJSON. Commands write
+// such output as results arrive rather than after the last one.
+type StreamFormatter interface {
+	// FormatSummary renders the record that ends the output when
+	// Runtime.Summary is set.
+	FormatSummary(Summary) (string, error)
+}
+
+// RequestRecorder is implemented by formatters that record what the
+// command was asked for alongside its results, such as the JSON
+// envelope. Commands call SetRequest before formatting.
+type RequestRecorder interface {
+	SetRequest(Request)
+}
+
+// Request describes what a command was asked for.
+type Request struct {
+	// Command is the name of the command.
+	Command string
+	// Query is the search query, or empty.
+	Query string
+	// Paged reports whether the results were paged, with Limit and
+	// Offset.
+	Paged bool
+	// Limit is the most results asked for, or zero for no limit.
+	Limit int
+	// Offset is the number of results skipped.
+	Offset int
+}
+
+// Summary describes the outcome of a streamed command.
+type Summary struct {
+	// Count is the number of results written.
+	Count int
+	// Failed is the number of results that could not be fetched.
+	Failed int
+	// Err is the error the command ended with, if any.
+	Err error
+}
+
 // Runtime holds the shared dependencies required by all commands.
 type Runtime struct {
-	Client *grokipedia.Client
-	Output OutputMode
+	// Context is cancelled on interrupt or when the global timeout expires.
+	Context context.Context
+	Client  *grokipedia.Client
+	Output  OutputMode
+	// Cache is the on-disk response cache, or nil if it is unavailable.
+	Cache *httpcache.Cache
+	// Offline is set when requests may only be served from Cache.
+	Offline bool
+	// Config holds the loaded configuration file and environment.
+	Config *config.Config
+	// SearchLimit is the default number of search results.
+	SearchLimit int
+	// Color is set when output may be styled with ANSI escapes.
+	Color bool
+	// Width is the terminal width used to wrap text output, or zero
+	// when output is not a terminal.
+	Width int
+	// Height is the terminal height, or zero when unknown.
+	Height int
+	// Pager is the command used to page long output, or empty when
+	// paging is disabled.
+	Pager string
+	// Summary ends streamed output with a summary record.
+	Summary bool
+	// Fields selects the fields of each result that are written, for
+	// formats that support it. Empty means all of them.
+	Fields []string
+	// Template is the text/template used by the template format.
+	Template string
+	// Query is the jq-style expression applied to JSON output, or empty.
+	Query string
+	// Envelope wraps JSON output in an object describing the request.
+	Envelope bool
 }
@@ -6,12 +6,20 @@ import "fmt"
 type Error struct {
 	Message string
 	Usage   bool
+	// Err is the underlying cause, if any. It is exposed through Unwrap
+	// so callers can classify failures with errors.Is and errors.As.
+	Err error
 }
 
 func (e *Error) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
 // IsUsage returns true if the error was caused by incorrect usage.
 func (e *Error) IsUsage() bool {
 	return e.Usage
@@ -27,7 +35,9 @@ func NewUsageError(msg string) *Error {
 	return &Error{Message: msg, Usage: true}
 }
 
-// NewRuntimeError creates a new Error for runtime failures.
+// NewRuntimeError creates a new Error for runtime failures. The format
+// accepts %w to keep the cause available to errors.Is and errors.As.
 func NewRuntimeError(msg string, args ...interface{}) *Error {
-	return &Error{Message: fmt.Sprintf(msg, args...), Usage: false}
+	err := fmt.Errorf(msg, args...)
+	return &Error{Message: err.Error(), Usage: false, Err: err}
 }
@@ -0,0 +1,24 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"grokir/internal/pager"
+)
+
+// Print writes command output to stdout. When a pager is configured and
+// the output does not fit on the terminal, it is shown through the
+// pager instead.
+func (rt Runtime) Print(output string) error {
+	if rt.Pager != "" && (rt.Height <= 0 || strings.Count(output, "\n") >= rt.Height) {
+		err := pager.Run(rt.Pager, output, os.Stdout, os.Stderr)
+		if !errors.Is(err, pager.ErrNotStarted) {
+			return err
+		}
+	}
+	fmt.Print(output)
+	return nil
+}
@@ -28,7 +28,7 @@ func Names() []string {
 func Run(ctx Runtime, name string, args []string) error {
 	cmd, ok := Get(name)
 	if !ok {
-		return NewError(fmt.Sprintf("unknown command: %s", name), false)
+		return NewUsageError(fmt.Sprintf("unknown command: %s", name))
 	}
 	return cmd.Run(ctx, args)
 }
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"grokir/internal/cli/command"
+)
+
+type cacheCommand struct{}
+
+func init() {
+	command.Register(&cacheCommand{})
+}
+
+func (c *cacheCommand) Name() string {
+	return "cache"
+}
+
+func (c *cacheCommand) Usage() string {
+	return "grokir cache <stats|clear|prune>"
+}
+
+func (c *cacheCommand) Outputs() []command.OutputMode {
+	return []command.OutputMode{command.OutputText, command.OutputJSON}
+}
+
+func (c *cacheCommand) Run(rt command.Runtime, args []string) error {
+	if len(args) != 1 {
+		return command.NewUsageError("usage: " + c.Usage())
+	}
+	if rt.Cache == nil {
+		return command.NewRuntimeError("cache is not available")
+	}
+
+	switch args[0] {
+	case "stats":
+		st, err := rt.Cache.Stats()
+		if err != nil {
+			return command.NewRuntimeError("cache error: %w", err)
+		}
+		if rt.Output == command.OutputJSON {
+			data, err := json.MarshalIndent(st, "", "  ")
+			if err != nil {
+				return command.NewRuntimeError("formatting error: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		fmt.Printf("Directory: %s\n", st.Dir)
+		fmt.Printf("Entries: %d (%d expired)\n", st.Entries, st.Expired)
+		fmt.Printf("Size: %d bytes\n", st.Bytes)
+	case "clear":
+		n, err := rt.Cache.Clear()
+		if err != nil {
+			return command.NewRuntimeError("cache error: %w", err)
+		}
+		fmt.Printf("Removed %d entries\n", n)
+	case "prune":
+		n, err := rt.Cache.Prune()
+		if err != nil {
+			return command.NewRuntimeError("cache error: %w", err)
+		}
+		fmt.Printf("Removed %d expired entries\n", n)
+	default:
+		return command.NewUsageError(fmt.Sprintf("unknown cache action: %s", args[0]))
+	}
+	return nil
+}
+
+var _ command.OutputCommand = (*cacheCommand)(nil)
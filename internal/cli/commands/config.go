@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/config"
+)
+
+type configCommand struct{}
+
+func init() {
+	command.Register(&configCommand{})
+}
+
+func (c *configCommand) Name() string {
+	return "config"
+}
+
+func (c *configCommand) Usage() string {
+	return "grokir config <get <key>|set <key> <value>|list|path>"
+}
+
+// configEntry is the JSON representation of a resolved setting.
+type configEntry struct {
+	Key    string        `json:"key"`
+	Value  string        `json:"value"`
+	Source config.Source `json:"source"`
+	Env    string        `json:"env"`
+	// Error says why the value is invalid, if it is.
+	Error string `json:"error,omitempty"`
+}
+
+func (c *configCommand) Outputs() []command.OutputMode {
+	return []command.OutputMode{command.OutputText, command.OutputJSON}
+}
+
+func (c *configCommand) Run(rt command.Runtime, args []string) error {
+	if len(args) < 1 {
+		return command.NewUsageError("usage: " + c.Usage())
+	}
+	if rt.Config == nil {
+		return command.NewRuntimeError("configuration is not available")
+	}
+
+	switch action, rest := args[0], args[1:]; action {
+	case "path":
+		if len(rest) != 0 {
+			return command.NewUsageError("usage: grokir config path")
+		}
+		fmt.Println(rt.Config.Path())
+	case "get":
+		if len(rest) != 1 {
+			return command.NewUsageError("usage: grokir config get <key>")
+		}
+		v, _, err := rt.Config.Get(rest[0])
+		if err != nil {
+			return command.NewRuntimeError("config error: %w", err)
+		}
+		fmt.Println(v)
+	case "set":
+		if len(rest) != 2 {
+			return command.NewUsageError("usage: grokir config set <key> <value>")
+		}
+		if err := rt.Config.Set(rest[0], rest[1]); err != nil {
+			return command.NewRuntimeError("config error: %w", err)
+		}
+		if err := rt.Config.Save(); err != nil {
+			return command.NewRuntimeError("config error: %w", err)
+		}
+	case "list":
+		if len(rest) != 0 {
+			return command.NewUsageError("usage: grokir config list")
+		}
+		return c.list(rt)
+	default:
+		return command.NewUsageError(fmt.Sprintf("unknown config action: %s", action))
+	}
+	return nil
+}
+
+func (c *configCommand) list(rt command.Runtime) error {
+	var entries []configEntry
+	for _, k := range config.Keys() {
+		// Invalid values are listed too, so that they can be found and
+		// fixed.
+		v, src, err := rt.Config.Get(k.Name)
+		e := configEntry{Key: k.Name, Value: v, Source: src, Env: k.Env}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		entries = append(entries, e)
+	}
+
+	if rt.Output == command.OutputJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return command.NewRuntimeError("formatting error: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, e := range entries {
+		if e.Error != "" {
+			fmt.Printf("%s = %q (%s; %s)\n", e.Key, e.Value, e.Source, e.Error)
+			continue
+		}
+		fmt.Printf("%s = %q (%s)\n", e.Key, e.Value, e.Source)
+	}
+	return nil
+}
+
+var _ command.OutputCommand = (*configCommand)(nil)
@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/cli/formatter"
+	"grokir/internal/grokipedia"
+)
+
+type exportCommand struct{}
+
+func init() {
+	command.Register(&exportCommand{})
+}
+
+func (c *exportCommand) Name() string {
+	return "export"
+}
+
+func (c *exportCommand) Usage() string {
+	return "grokir export [--format md|html|epub] [--out <path>] [--title <title>] [-q <query>] [--max <num>] [-f <file>] [-c <num>] <slug>..."
+}
+
+func (c *exportCommand) Run(rt command.Runtime, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Print(c.Usage())
+	}
+	defaultFormat := "md"
+	if rt.Output == command.OutputHTML {
+		defaultFormat = "html"
+	}
+	format := fs.String("format", defaultFormat, "export format: md, html or epub")
+	out := fs.String("out", ".", "directory the files are written to, or the EPUB file")
+	title := fs.String("title", "", "EPUB book title (default: made from the page titles)")
+	query := fs.String("q", "", "export the top results of a search")
+	maxResults := fs.Int("max", 10, "number of search results exported with -q")
+	file := fs.String("f", "", "read slugs from a file, one per line (- for stdin)")
+	concurrency := fs.Int("c", grokipedia.DefaultConcurrency, "number of pages fetched in parallel")
+
+	if err := fs.Parse(args); err != nil {
+		return command.NewUsageError(err.Error())
+	}
+
+	var ext string
+	switch *format {
+	case "md", "markdown":
+		ext = ".md"
+	case "html":
+		ext = ".html"
+	case "epub":
+		ext = ".epub"
+	default:
+		return command.NewUsageError(fmt.Sprintf("unsupported export format: %s", *format))
+	}
+
+	slugs := fs.Args()
+	if *file != "" {
+		fromFile, err := readSlugs(*file)
+		if err != nil {
+			return command.NewRuntimeError("reading slugs: %w", err)
+		}
+		slugs = append(slugs, fromFile...)
+	}
+	if *query != "" {
+		if *maxResults <= 0 {
+			return command.NewUsageError("--max must be positive")
+		}
+		for r, err := range rt.Client.SearchAll(rt.Context, *query, grokipedia.SearchOptions{Max: *maxResults}) {
+			if err != nil {
+				return command.NewRuntimeError("search failed: %w", err)
+			}
+			slugs = append(slugs, r.Slug)
+		}
+	}
+	slugs = uniqueSlugs(slugs)
+	if len(slugs) == 0 {
+		if *query != "" {
+			return command.NewRuntimeError("no results found for %q", *query)
+		}
+		return command.NewUsageError("missing page slug")
+	}
+
+	results := rt.Client.GetPages(rt.Context, slugs, grokipedia.GetPagesOptions{
+		Concurrency:    *concurrency,
+		IncludeContent: true,
+	})
+	fetchedAt := time.Now()
+	pages, failed := collectPages(rt, results)
+	if len(pages) == 0 {
+		return failed
+	}
+	// Slugs that resolve to the same page export it once; links using
+	// any of them lead to it.
+	pages = uniquePages(pages)
+
+	if ext == ".epub" {
+		path := *out
+		if !strings.HasSuffix(path, ".epub") {
+			name := *title
+			switch {
+			case name != "":
+			case *query != "":
+				name = *query
+			case len(pages) == 1:
+				name = pages[0].Slug
+			default:
+				name = "grokipedia"
+			}
+			path = filepath.Join(path, exportFileName(name, ext))
+		}
+		aliases := make(map[string]string)
+		for _, r := range results {
+			if r.Page != nil {
+				aliases[r.Slug] = r.Page.Slug
+			}
+		}
+		f := &formatter.EPUBFormatter{
+			BaseURL:   rt.Client.BaseURL,
+			Title:     *title,
+			FetchedAt: fetchedAt,
+			Aliases:   aliases,
+		}
+		if err := writeEPUB(path, f, pages); err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return failed
+	}
+
+	files := exportFiles(results, ext)
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return command.NewRuntimeError("creating output directory: %w", err)
+	}
+	var f command.PageFormatter
+	if ext == ".html" {
+		f = &formatter.HTMLFormatter{BaseURL: rt.Client.BaseURL, Files: files}
+	} else {
+		f = &formatter.MarkdownFormatter{
+			BaseURL:     rt.Client.BaseURL,
+			FrontMatter: true,
+			FetchedAt:   fetchedAt,
+			Files:       files,
+		}
+	}
+	for _, page := range pages {
+		doc, err := f.FormatPage(page)
+		if err != nil {
+			return command.NewRuntimeError("formatting error: %w", err)
+		}
+		path := filepath.Join(*out, files[page.Slug])
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			return command.NewRuntimeError("writing %s: %w", page.Slug, err)
+		}
+		fmt.Println(path)
+	}
+	return failed
+}
+
+// writeEPUB writes pages as an EPUB book to path, creating its
+// directory if needed.
+func writeEPUB(path string, f *formatter.EPUBFormatter, pages []*grokipedia.Page) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return command.NewRuntimeError("creating output directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return command.NewRuntimeError("creating %s: %w", path, err)
+	}
+	if err := f.WriteBook(file, pages); err != nil {
+		file.Close()
+		return command.NewRuntimeError("writing %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return command.NewRuntimeError("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// uniqueSlugs returns slugs without repeats, keeping the first
+// occurrence of each.
+func uniqueSlugs(slugs []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, s := range slugs {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// uniquePages returns pages without repeats of a canonical slug,
+// keeping the first occurrence of each.
+func uniquePages(pages []*grokipedia.Page) []*grokipedia.Page {
+	seen := make(map[string]bool)
+	var out []*grokipedia.Page
+	for _, p := range pages {
+		if !seen[p.Slug] {
+			seen[p.Slug] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// exportFiles returns the file name of each fetched page, keyed by both
+// the requested slug and the canonical one returned by the API, since
+// links may use either. Pages whose slugs make the same file name get
+// numbered names, so that none overwrites another.
+func exportFiles(results []grokipedia.PageResult, ext string) map[string]string {
+	files := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, r := range results {
+		if r.Page == nil {
+			continue
+		}
+		name, ok := files[r.Page.Slug]
+		if !ok {
+			name = uniqueFileName(exportFileName(r.Page.Slug, ext), taken)
+		}
+		files[r.Slug] = name
+		files[r.Page.Slug] = name
+	}
+	return files
+}
+
+// exportFileName returns a file name for the page slug that is safe on
+// common file systems.
+func exportFileName(slug, ext string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, slug)
+	if name == "" || strings.HasPrefix(name, ".") {
+		name = "_" + name
+	}
+	return name + ext
+}
+
+// uniqueFileName returns name, numbered before its extension if it is
+// already taken, and marks the result taken. Names are compared without
+// regard to case, as case-insensitive file systems do.
+func uniqueFileName(name string, taken map[string]bool) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; taken[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s_%d%s", base, i, ext)
+	}
+	taken[strings.ToLower(name)] = true
+	return name
+}
+
+var _ command.Command = (*exportCommand)(nil)
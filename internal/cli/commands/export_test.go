@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"maps"
+	"testing"
+
+	"grokir/internal/grokipedia"
+)
+
+func TestExportFileName(t *testing.T) {
+	tests := []struct {
+		slug string
+		want string
+	}{
+		{"Go", "Go.md"},
+		{"a/b", "a_b.md"},
+		{`C:\x*?"<>|`, "C__x______.md"},
+		{".hidden", "_.hidden.md"},
+		{"", "_.md"},
+		{"Café", "Café.md"},
+	}
+	for _, tt := range tests {
+		if got := exportFileName(tt.slug, ".md"); got != tt.want {
+			t.Errorf("exportFileName(%q) = %q, want %q", tt.slug, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueFileName(t *testing.T) {
+	taken := make(map[string]bool)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a_b.md", "a_b.md"},
+		{"a_b.md", "a_b_2.md"},
+		{"Foo.md", "Foo.md"},
+		{"foo.md", "foo_2.md"},
+		{"a_b.md", "a_b_3.md"},
+		{"a_b_2.md", "a_b_2_2.md"},
+	}
+	for _, tt := range tests {
+		if got := uniqueFileName(tt.name, taken); got != tt.want {
+			t.Errorf("uniqueFileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExportFiles(t *testing.T) {
+	page := func(slug string) *grokipedia.Page { return &grokipedia.Page{Slug: slug} }
+	results := []grokipedia.PageResult{
+		{Slug: "a/b", Page: page("a/b")},
+		{Slug: "a_b", Page: page("a_b")},
+		{Slug: "Foo", Page: page("Foo")},
+		{Slug: "foo", Page: page("foo")},
+		{Slug: "golang", Page: page("Go")},
+		{Slug: "Go", Page: page("Go")},
+		{Slug: "missing"},
+	}
+	want := map[string]string{
+		"a/b":    "a_b.md",
+		"a_b":    "a_b_2.md",
+		"Foo":    "Foo.md",
+		"foo":    "foo_2.md",
+		"golang": "Go.md",
+		"Go":     "Go.md",
+	}
+	if got := exportFiles(results, ".md"); !maps.Equal(got, want) {
+		t.Errorf("exportFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestUniquePages(t *testing.T) {
+	goPage := &grokipedia.Page{Slug: "Go"}
+	pages := []*grokipedia.Page{goPage, {Slug: "Rust"}, {Slug: "Go"}}
+	got := uniquePages(pages)
+	if len(got) != 2 || got[0] != goPage || got[1].Slug != "Rust" {
+		t.Errorf("uniquePages() = %v, want Go then Rust", got)
+	}
+}
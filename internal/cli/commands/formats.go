@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/cli/formatter"
+)
+
+type formatsCommand struct{}
+
+func init() {
+	command.Register(&formatsCommand{})
+}
+
+func (c *formatsCommand) Name() string {
+	return "formats"
+}
+
+func (c *formatsCommand) Usage() string {
+	return "grokir formats"
+}
+
+type formatEntry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Commands    []string `json:"commands"`
+}
+
+func (c *formatsCommand) Outputs() []command.OutputMode {
+	return []command.OutputMode{command.OutputText, command.OutputJSON}
+}
+
+func (c *formatsCommand) Run(rt command.Runtime, args []string) error {
+	if len(args) != 0 {
+		return command.NewUsageError("usage: " + c.Usage())
+	}
+
+	names := command.Names()
+	slices.Sort(names)
+	var entries []formatEntry
+	for _, f := range formatter.Formats() {
+		e := formatEntry{Name: f.Name, Description: f.Description, Commands: []string{}}
+		for _, name := range names {
+			cmd, _ := command.Get(name)
+			switch cmd.(type) {
+			case command.FormattedCommand, command.OutputCommand:
+				if slices.Contains(formatter.ForCommand(cmd), f.Name) {
+					e.Commands = append(e.Commands, name)
+				}
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	if rt.Output == command.OutputJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return command.NewRuntimeError("formatting error: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, e := range entries {
+		fmt.Printf("%-10s %s (%s)\n", e.Name, e.Description, strings.Join(e.Commands, ", "))
+	}
+	return nil
+}
+
+var _ command.OutputCommand = (*formatsCommand)(nil)
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"errors"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/cli/formatter"
+)
+
+// formatError returns the command error for a formatter failure. Asking
+// for fields that do not exist is a usage error.
+func formatError(err error) error {
+	if errors.Is(err, formatter.ErrUnknownField) {
+		return command.NewUsageError(err.Error())
+	}
+	return command.NewRuntimeError("formatting error: %w", err)
+}
+
+// setRequest tells formatters that record the request, such as the JSON
+// envelope, what the command was asked for.
+func setRequest(f any, r command.Request) {
+	if rr, ok := f.(command.RequestRecorder); ok {
+		rr.SetRequest(r)
+	}
+}
+
+// jsonErrors reports whether errors are reported as JSON objects, as
+// they are when the output is JSON.
+func jsonErrors(rt command.Runtime) bool {
+	f, ok := formatter.Lookup(string(rt.Output))
+	return ok && f.JSON
+}
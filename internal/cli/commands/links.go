@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/cli/formatter"
+	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
+)
+
+type linksCommand struct{}
+
+func init() {
+	command.Register(&linksCommand{})
+}
+
+func (c *linksCommand) Name() string {
+	return "links"
+}
+
+func (c *linksCommand) Usage() string {
+	return "grokir links [--validate] [-c <num>] <slug>"
+}
+
+func (c *linksCommand) Results() []command.ResultKind {
+	return []command.ResultKind{command.ResultLinks}
+}
+
+func (c *linksCommand) Run(rt command.Runtime, args []string) error {
+	fs := flag.NewFlagSet("links", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Print(c.Usage())
+	}
+	validate := fs.Bool("validate", false, "ask the API to validate links and check that internal link targets exist")
+	concurrency := fs.Int("c", grokipedia.DefaultConcurrency, "number of links checked in parallel")
+
+	if err := fs.Parse(args); err != nil {
+		return command.NewUsageError(err.Error())
+	}
+	if fs.NArg() != 1 {
+		return command.NewUsageError("expected a single page slug")
+	}
+	slug := fs.Arg(0)
+
+	page, err := rt.Client.GetPageContext(rt.Context, slug, true, *validate)
+	if rt.Offline && errors.Is(err, httpcache.ErrNotCached) {
+		return command.NewRuntimeError("%w: page %q has not been cached", httpcache.ErrNotCached, slug)
+	}
+	if err != nil {
+		return command.NewRuntimeError("page retrieval error: %w", err)
+	}
+
+	links := page.Links(rt.Client.BaseURL)
+	var checkErr error
+	// The page response does not say which links the API found broken,
+	// so each internal link target is also requested.
+	if *validate {
+		checkErr = rt.Client.ValidateLinks(rt.Context, links.Internal, grokipedia.GetPagesOptions{
+			Concurrency: *concurrency,
+		})
+	}
+
+	f, err := formatter.NewLinksFormatter(rt)
+	if err != nil {
+		return command.NewUsageError(err.Error())
+	}
+	setRequest(f, command.Request{Command: c.Name()})
+	output, err := f.FormatLinks(links)
+	if err != nil {
+		return formatError(err)
+	}
+	if err := rt.Print(output); err != nil {
+		return command.NewRuntimeError("output error: %w", err)
+	}
+
+	if checkErr != nil {
+		return command.NewRuntimeError("validating links: %w", checkErr)
+	}
+	var broken int
+	for _, l := range links.Internal {
+		if l.Broken {
+			broken++
+		}
+	}
+	if broken > 0 {
+		return command.NewRuntimeError("%d of %d internal links are broken", broken, len(links.Internal))
+	}
+	return nil
+}
+
+var _ command.FormattedCommand = (*linksCommand)(nil)
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"net/url"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+// offlineIndex builds a local search index over every page stored in
+// the response cache.
+func offlineIndex(rt command.Runtime) (*grokipedia.Index, error) {
+	var pages []*grokipedia.Page
+	err := rt.Cache.Each(func(rawURL string, body []byte) error {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Path != grokipedia.PageEndpoint {
+			return nil
+		}
+		if page, err := grokipedia.DecodePage(body); err == nil {
+			pages = append(pages, page)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grokipedia.NewIndex(pages), nil
+}
@@ -1,10 +1,18 @@
 package commands
 
 import (
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"grokir/internal/cli/command"
 	"grokir/internal/cli/formatter"
+	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
 )
 
 type pageCommand struct{}
@@ -18,30 +26,262 @@ func (c *pageCommand) Name() string {
 }
 
 func (c *pageCommand) Usage() string {
-	return "grokir page <slug>"
+	return "grokir page [-f <file>] [-c <num>] <slug>...\n" +
+		"       grokir page [--toc] [--section <heading>] <slug>"
+}
+
+func (c *pageCommand) Results() []command.ResultKind {
+	return []command.ResultKind{command.ResultPage}
 }
 
 func (c *pageCommand) Run(rt command.Runtime, args []string) error {
-	if len(args) < 1 {
+	fs := flag.NewFlagSet("page", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Print(c.Usage())
+	}
+	file := fs.String("f", "", "read slugs from a file, one per line (- for stdin)")
+	concurrency := fs.Int("c", grokipedia.DefaultConcurrency, "number of pages fetched in parallel")
+	toc := fs.Bool("toc", false, "list the page sections instead of the content")
+	section := fs.String("section", "", "print only the section whose heading matches")
+
+	if err := fs.Parse(args); err != nil {
+		return command.NewUsageError(err.Error())
+	}
+
+	slugs := fs.Args()
+	if len(slugs) == 1 && slugs[0] == "-" {
+		slugs = nil
+		*file = "-"
+	}
+	if *file != "" {
+		fromFile, err := readSlugs(*file)
+		if err != nil {
+			return command.NewRuntimeError("reading slugs: %w", err)
+		}
+		slugs = append(slugs, fromFile...)
+	}
+
+	if len(slugs) < 1 {
 		return command.NewUsageError("missing page slug")
 	}
+	if !*toc && *section == "" {
+		f, err := formatter.NewPageFormatter(rt)
+		if err != nil {
+			return command.NewUsageError(err.Error())
+		}
+		if sf, ok := f.(command.StreamFormatter); ok {
+			return c.stream(rt, f, sf, slugs, *concurrency)
+		}
+	}
+	if len(slugs) == 1 {
+		return c.runSingle(rt, slugs[0], *toc, *section)
+	}
+	if *toc || *section != "" {
+		return command.NewUsageError("--toc and --section take a single slug")
+	}
+
+	results := rt.Client.GetPages(rt.Context, slugs, grokipedia.GetPagesOptions{
+		Concurrency:    *concurrency,
+		IncludeContent: true,
+	})
+	return c.printBatch(rt, results)
+}
+
+func (c *pageCommand) runSingle(rt command.Runtime, slug string, toc bool, section string) error {
+	page, err := rt.Client.GetPageContext(rt.Context, slug, true, false)
+	if rt.Offline && errors.Is(err, httpcache.ErrNotCached) {
+		return command.NewRuntimeError("%w: page %q has not been cached", httpcache.ErrNotCached, slug)
+	}
+	if err != nil {
+		return command.NewRuntimeError("page retrieval error: %w", err)
+	}
 
-	slug := args[0]
+	sections := page.Sections()
+	if section != "" {
+		s, ok := grokipedia.FindSection(sections, section)
+		if !ok {
+			return command.NewRuntimeError("no section matching %q in %s", section, slug)
+		}
+		sections = []grokipedia.Section{*s}
+		only := *page
+		only.Description = ""
+		only.Content = s.Markdown()
+		page = &only
+	}
+
+	f, err := formatter.NewPageFormatter(rt)
+	if err != nil {
+		return command.NewUsageError(err.Error())
+	}
+	setRequest(f, command.Request{Command: c.Name()})
 
-	page, err := rt.Client.GetPage(slug, true, false)
+	var output string
+	if toc {
+		tf, ok := f.(command.TOCFormatter)
+		if !ok {
+			return command.NewUsageError("--toc is not supported by this output format")
+		}
+		output, err = tf.FormatTOC(page, sections)
+	} else {
+		output, err = f.FormatPage(page)
+	}
 	if err != nil {
-		return command.NewRuntimeError("page retrieval error: %v", err)
+		return formatError(err)
 	}
 
-	f := formatter.NewPageFormatter(rt.Output)
+	if err := rt.Print(output); err != nil {
+		return command.NewRuntimeError("output error: %w", err)
+	}
+	return nil
+}
+
+// printBatch prints every page that was fetched and reports the slugs
+// that failed on stderr, so one bad slug does not hide the others.
+func (c *pageCommand) printBatch(rt command.Runtime, results []grokipedia.PageResult) error {
+	pages, failed := collectPages(rt, results)
 
-	output, err := f.FormatPage(page)
+	f, err := formatter.NewPageFormatter(rt)
+	if err != nil {
+		return command.NewUsageError(err.Error())
+	}
+	setRequest(f, command.Request{Command: c.Name()})
+	output, err := formatPages(f, pages)
 	if err != nil {
-		return command.NewRuntimeError("formatting error: %v", err)
+		return formatError(err)
+	}
+	if err := rt.Print(output); err != nil {
+		return command.NewRuntimeError("output error: %w", err)
+	}
+	return failed
+}
+
+// stream writes each page as soon as it and the pages before it have
+// been fetched, and reports each slug that failed as soon as it fails.
+func (c *pageCommand) stream(rt command.Runtime, f command.PageFormatter, sf command.StreamFormatter, slugs []string, concurrency int) error {
+	s := &streamer{rt: rt, f: sf}
+	errs := &pageErrors{rt: rt}
+	for r := range rt.Client.GetPagesSeq(rt.Context, slugs, grokipedia.GetPagesOptions{
+		Concurrency:    concurrency,
+		IncludeContent: true,
+	}) {
+		errs.report(r)
+		if r.Err != nil {
+			s.failed++
+			continue
+		}
+		if err := s.write(f.FormatPage(r.Page)); err != nil {
+			return s.end(err)
+		}
+	}
+	return s.end(errs.err())
+}
+
+// collectPages returns the pages that were fetched and reports the slugs
+// that failed as pageErrors does. The returned error summarizes the
+// failures, or is nil when every page was fetched.
+func collectPages(rt command.Runtime, results []grokipedia.PageResult) ([]*grokipedia.Page, error) {
+	var pages []*grokipedia.Page
+	errs := &pageErrors{rt: rt}
+	for _, r := range results {
+		errs.report(r)
+		if r.Err == nil {
+			pages = append(pages, r.Page)
+		}
+	}
+	return pages, errs.err()
+}
+
+// pageErrors reports the slugs of a batch that failed on stderr, as JSON
+// objects when the output is JSON. Slugs missing from the cache when
+// offline are listed together once the batch is done.
+type pageErrors struct {
+	rt        command.Runtime
+	total     int
+	failed    int
+	notCached []string
+	first     error
+}
+
+// report reports the failure of r, if it failed.
+func (e *pageErrors) report(r grokipedia.PageResult) {
+	e.total++
+	err := r.Err
+	switch {
+	case err == nil:
+		return
+	case jsonErrors(e.rt):
+		fmt.Fprint(os.Stderr, formatter.FormatErrorJSON(err, r.Slug))
+	case e.rt.Offline && errors.Is(err, httpcache.ErrNotCached):
+		e.notCached = append(e.notCached, r.Slug)
+		err = httpcache.ErrNotCached
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", r.Slug, err)
+	}
+	e.failed++
+	if e.first == nil {
+		e.first = err
 	}
+}
 
-	fmt.Print(output)
+// err finishes the report and returns an error summarizing the
+// failures, or nil when every page was fetched.
+func (e *pageErrors) err() error {
+	if len(e.notCached) > 0 {
+		fmt.Fprintf(os.Stderr, "not available offline: %s\n", strings.Join(e.notCached, ", "))
+	}
+	if e.first != nil {
+		return command.NewRuntimeError("%d of %d pages failed: %w", e.failed, e.total, e.first)
+	}
 	return nil
 }
 
-var _ command.Command = (*pageCommand)(nil)
+// formatPages renders pages as one document when the formatter supports
+// it, and as consecutive single-page documents otherwise.
+func formatPages(f command.PageFormatter, pages []*grokipedia.Page) (string, error) {
+	if pf, ok := f.(command.PagesFormatter); ok {
+		return pf.FormatPages(pages)
+	}
+
+	var b strings.Builder
+	for i, page := range pages {
+		out, err := f.FormatPage(page)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(strings.TrimRight(out, "\n"))
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// readSlugs reads one slug per line from path, or from stdin when path
+// is "-". Blank lines and lines starting with # are skipped.
+func readSlugs(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var slugs []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		slugs = append(slugs, line)
+	}
+	return slugs, sc.Err()
+}
+
+var _ command.FormattedCommand = (*pageCommand)(nil)
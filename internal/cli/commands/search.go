@@ -1,12 +1,16 @@
 package commands
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"iter"
 	"strings"
 
 	"grokir/internal/cli/command"
 	"grokir/internal/cli/formatter"
+	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
 )
 
 type searchCommand struct{}
@@ -20,7 +24,11 @@ func (c *searchCommand) Name() string {
 }
 
 func (c *searchCommand) Usage() string {
-	return "grokir search <query> [-l <num>] [-o <num>]"
+	return "grokir search <query> [-l <num>] [-o <num>] [--all] [--max <num>]"
+}
+
+func (c *searchCommand) Results() []command.ResultKind {
+	return []command.ResultKind{command.ResultSearch}
 }
 
 func (c *searchCommand) Run(rt command.Runtime, args []string) error {
@@ -28,8 +36,14 @@ func (c *searchCommand) Run(rt command.Runtime, args []string) error {
 	fs.Usage = func() {
 		fmt.Print(c.Usage())
 	}
-	limit := fs.Int("l", 10, "maximum number of results")
+	defaultLimit := rt.SearchLimit
+	if defaultLimit <= 0 {
+		defaultLimit = 10
+	}
+	limit := fs.Int("l", defaultLimit, "maximum number of results (page size with --all or --max)")
 	offset := fs.Int("o", 0, "offset for pagination")
+	all := fs.Bool("all", false, "fetch every result page")
+	maxResults := fs.Int("max", 0, "fetch result pages until this many results")
 
 	if err := fs.Parse(args); err != nil {
 		return command.NewUsageError(err.Error())
@@ -38,15 +52,34 @@ func (c *searchCommand) Run(rt command.Runtime, args []string) error {
 	if fs.NArg() < 1 {
 		return command.NewUsageError("missing search query")
 	}
+	if *maxResults < 0 {
+		return command.NewUsageError("--max must not be negative")
+	}
 
 	query := strings.Join(fs.Args(), " ")
 
-	results, err := rt.Client.Search(query, *limit, *offset)
+	f, err := formatter.NewSearchFormatter(rt)
 	if err != nil {
-		return command.NewRuntimeError("search error: %v", err)
+		return command.NewUsageError(err.Error())
 	}
+	request := command.Request{Command: c.Name(), Query: query, Paged: true, Limit: *limit, Offset: *offset}
+	if *all || *maxResults > 0 {
+		request.Limit = *maxResults
+	}
+	setRequest(f, request)
 
-	f := formatter.NewSearchFormatter(rt.Output)
+	seq := c.search(rt, query, *limit, *offset, *maxResults, *all || *maxResults > 0)
+	if sf, ok := f.(command.StreamFormatter); ok {
+		return c.stream(rt, f, sf, seq)
+	}
+
+	var results []grokipedia.SearchResult
+	for r, err := range seq {
+		if err != nil {
+			return command.NewRuntimeError("search error: %w", err)
+		}
+		results = append(results, r)
+	}
 
 	if len(results) == 0 {
 		fmt.Print(f.NoResults())
@@ -55,11 +88,88 @@ func (c *searchCommand) Run(rt command.Runtime, args []string) error {
 
 	output, err := f.FormatSearch(results)
 	if err != nil {
-		return command.NewRuntimeError("formatting error: %v", err)
+		return formatError(err)
 	}
 
-	fmt.Print(output)
+	if err := rt.Print(output); err != nil {
+		return command.NewRuntimeError("output error: %w", err)
+	}
 	return nil
 }
 
-var _ command.Command = (*searchCommand)(nil)
+// search returns the results for query: every result page when
+// paginate is set, otherwise a single page. Offline, a search that has
+// not been cached is answered from the cached pages instead.
+func (c *searchCommand) search(rt command.Runtime, query string, limit, offset, maxResults int, paginate bool) iter.Seq2[grokipedia.SearchResult, error] {
+	var seq iter.Seq2[grokipedia.SearchResult, error]
+	if paginate {
+		seq = rt.Client.SearchAll(rt.Context, query, grokipedia.SearchOptions{
+			PageSize: limit,
+			Offset:   offset,
+			Max:      maxResults,
+		})
+	} else {
+		seq = func(yield func(grokipedia.SearchResult, error) bool) {
+			results, err := rt.Client.SearchContext(rt.Context, query, limit, offset)
+			if err != nil {
+				yield(grokipedia.SearchResult{}, err)
+				return
+			}
+			for _, r := range results {
+				if !yield(r, nil) {
+					return
+				}
+			}
+		}
+	}
+	if !rt.Offline {
+		return seq
+	}
+
+	// Offline, results are buffered so that a search cached only in
+	// part is answered from the index as a whole.
+	return func(yield func(grokipedia.SearchResult, error) bool) {
+		var results []grokipedia.SearchResult
+		for r, err := range seq {
+			if errors.Is(err, httpcache.ErrNotCached) {
+				ix, err := offlineIndex(rt)
+				if err != nil {
+					yield(grokipedia.SearchResult{}, err)
+					return
+				}
+				n := limit
+				if paginate {
+					n = maxResults
+				}
+				results = ix.Search(query, n, offset)
+				break
+			}
+			if err != nil {
+				yield(grokipedia.SearchResult{}, err)
+				return
+			}
+			results = append(results, r)
+		}
+		for _, r := range results {
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+// stream writes each result as soon as it arrives.
+func (c *searchCommand) stream(rt command.Runtime, f command.SearchFormatter, sf command.StreamFormatter, seq iter.Seq2[grokipedia.SearchResult, error]) error {
+	s := &streamer{rt: rt, f: sf}
+	for r, err := range seq {
+		if err != nil {
+			return s.end(command.NewRuntimeError("search error: %w", err))
+		}
+		if err := s.write(f.FormatSearch([]grokipedia.SearchResult{r})); err != nil {
+			return s.end(err)
+		}
+	}
+	return s.end(nil)
+}
+
+var _ command.FormattedCommand = (*searchCommand)(nil)
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+
+	"grokir/internal/cli/command"
+)
+
+// streamer writes the output of a StreamFormatter to stdout record by
+// record, as results arrive. Streamed output is meant for other
+// programs, so it is never paged.
+type streamer struct {
+	rt     command.Runtime
+	f      command.StreamFormatter
+	count  int
+	failed int
+}
+
+// write prints the output formatted for one result.
+func (s *streamer) write(output string, err error) error {
+	if err != nil {
+		return formatError(err)
+	}
+	fmt.Print(output)
+	s.count++
+	return nil
+}
+
+// end finishes the stream with err, the error the command returns,
+// writing the summary record first if the runtime asks for one.
+func (s *streamer) end(err error) error {
+	if !s.rt.Summary {
+		return err
+	}
+	output, ferr := s.f.FormatSummary(command.Summary{Count: s.count, Failed: s.failed, Err: err})
+	if ferr != nil {
+		return command.NewRuntimeError("formatting error: %w", ferr)
+	}
+	fmt.Print(output)
+	return err
+}
@@ -0,0 +1,155 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+type searchColumn struct {
+	name  string
+	value func(f *CSVFormatter, r grokipedia.SearchResult) string
+}
+
+// searchColumns are the columns CSVFormatter can write for a search
+// result, in their default order.
+var searchColumns = []searchColumn{
+	{"slug", func(_ *CSVFormatter, r grokipedia.SearchResult) string { return r.Slug }},
+	{"title", func(_ *CSVFormatter, r grokipedia.SearchResult) string { return r.Title }},
+	{"relevance_score", func(_ *CSVFormatter, r grokipedia.SearchResult) string {
+		return strconv.FormatFloat(r.RelevanceScore, 'f', -1, 64)
+	}},
+	{"view_count", func(_ *CSVFormatter, r grokipedia.SearchResult) string { return strconv.FormatInt(r.ViewCount, 10) }},
+	{"snippet", func(_ *CSVFormatter, r grokipedia.SearchResult) string { return normalize(r.Snippet) }},
+	{"url", func(f *CSVFormatter, r grokipedia.SearchResult) string { return grokipedia.PageURL(f.BaseURL, r.Slug) }},
+}
+
+// CSVFormatter formats search results as a table of comma- or
+// tab-separated values for spreadsheets, with a header row. Numbers are
+// written without formatting.
+type CSVFormatter struct {
+	// Comma is the field separator.
+	Comma rune
+	// Fields are the columns written, in order. When empty, every
+	// column is written.
+	Fields []string
+	// BaseURL is the Grokipedia site the url column points to.
+	BaseURL string
+}
+
+func NewCSV() *CSVFormatter {
+	return &CSVFormatter{Comma: ',', BaseURL: grokipedia.DefaultBaseURL}
+}
+
+func NewTSV() *CSVFormatter {
+	return &CSVFormatter{Comma: '\t', BaseURL: grokipedia.DefaultBaseURL}
+}
+
+func newCSVFor(rt command.Runtime, comma rune) *CSVFormatter {
+	f := NewCSV()
+	f.Comma = comma
+	f.Fields = rt.Fields
+	if rt.Client != nil {
+		f.BaseURL = rt.Client.BaseURL
+	}
+	return f
+}
+
+func init() {
+	Register(Format{
+		Name:        "csv",
+		Description: "comma-separated values with a header row",
+		New:         func(rt command.Runtime) any { return newCSVFor(rt, ',') },
+		Fields:      csvFields,
+	})
+	Register(Format{
+		Name:        "tsv",
+		Description: "tab-separated values with a header row",
+		New:         func(rt command.Runtime) any { return newCSVFor(rt, '\t') },
+		Fields:      csvFields,
+	})
+}
+
+// SearchFields returns the names of the columns available for search
+// results.
+func SearchFields() []string {
+	names := make([]string, len(searchColumns))
+	for i, c := range searchColumns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// csvFields returns the columns that can be selected for results of
+// kind k: those of search results.
+func csvFields(k command.ResultKind) []string {
+	if k != command.ResultSearch {
+		return nil
+	}
+	return SearchFields()
+}
+
+// FormatSearch renders search results as a header row followed by one
+// row per result.
+func (f *CSVFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
+	columns, err := f.columns()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Comma = f.Comma
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = searchColumns[c].name
+	}
+	w.Write(header)
+	for _, r := range results {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = searchColumns[c].value(f, r)
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("CSV error: %w", err)
+	}
+	return b.String(), nil
+}
+
+// NoResults returns just the header row, so the output is still a
+// valid table.
+func (f *CSVFormatter) NoResults() string {
+	out, err := f.FormatSearch(nil)
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// columns returns the indexes in searchColumns of the selected fields.
+func (f *CSVFormatter) columns() ([]int, error) {
+	if len(f.Fields) == 0 {
+		all := make([]int, len(searchColumns))
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+	if err := checkFields(f.Fields, SearchFields()); err != nil {
+		return nil, err
+	}
+	var columns []int
+	for _, name := range f.Fields {
+		i := slices.IndexFunc(searchColumns, func(c searchColumn) bool { return c.name == name })
+		columns = append(columns, i)
+	}
+	return columns, nil
+}
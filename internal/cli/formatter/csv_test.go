@@ -0,0 +1,56 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"grokir/internal/grokipedia"
+)
+
+func TestCSVFormatter_FormatSearch(t *testing.T) {
+	results := []grokipedia.SearchResult{
+		{Slug: "go", Title: `Go, "the" language`, Snippet: "Fast\nand simple.", RelevanceScore: 0.25, ViewCount: 1234567},
+		{Slug: "rust", Title: "Rust", RelevanceScore: 12},
+	}
+
+	got, err := NewCSV().FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	want := "slug,title,relevance_score,view_count,snippet,url\n" +
+		`go,"Go, ""the"" language",0.25,1234567,Fast and simple.,https://grokipedia.com/page/go` + "\n" +
+		"rust,Rust,12,0,,https://grokipedia.com/page/rust\n"
+	if got != want {
+		t.Errorf("FormatSearch() =\n%s\nwant\n%s", got, want)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(got)).ReadAll()
+	if err != nil {
+		t.Fatalf("output does not parse as CSV: %v", err)
+	}
+	if rows[1][1] != results[0].Title {
+		t.Errorf("title read back = %q, want %q", rows[1][1], results[0].Title)
+	}
+}
+
+func TestCSVFormatter_Fields(t *testing.T) {
+	f := NewTSV()
+	f.Fields = []string{"view_count", "slug"}
+	got, err := f.FormatSearch([]grokipedia.SearchResult{{Slug: "a\tb", ViewCount: 5000}})
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	if want := "view_count\tslug\n5000\t\"a\tb\"\n"; got != want {
+		t.Errorf("FormatSearch() = %q, want %q", got, want)
+	}
+	if got := f.NoResults(); got != "view_count\tslug\n" {
+		t.Errorf("NoResults() = %q, want the header row", got)
+	}
+
+	f.Fields = []string{"slug", "views"}
+	if _, err := f.FormatSearch(nil); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("FormatSearch() with unknown field error = %v, want ErrUnknownField", err)
+	}
+}
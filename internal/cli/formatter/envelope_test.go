@@ -0,0 +1,250 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+// loadEnvelopeSchema reads the published schema of the JSON envelope.
+func loadEnvelopeSchema(t *testing.T) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile("envelope.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("envelope.schema.json: %v", err)
+	}
+	return schema
+}
+
+// validate checks v against the parts of JSON Schema the envelope schema
+// uses, returning a description of each mismatch.
+func validate(root, schema map[string]any, v any, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		def := root["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")]
+		return validate(root, def.(map[string]any), v, path)
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, v) {
+		return []string{fmt.Sprintf("%s = %v, want %v", path, v, c)}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, v) {
+		return []string{fmt.Sprintf("%s = %v, want one of %v", path, v, enum)}
+	}
+	if types, ok := schema["type"]; ok {
+		var allowed []string
+		switch t := types.(type) {
+		case string:
+			allowed = []string{t}
+		case []any:
+			for _, s := range t {
+				allowed = append(allowed, s.(string))
+			}
+		}
+		if !slices.Contains(allowed, jsonType(v)) && !(jsonType(v) == "integer" && slices.Contains(allowed, "number")) {
+			return []string{fmt.Sprintf("%s is %s, want %v", path, jsonType(v), allowed)}
+		}
+	}
+	if n, ok := v.(float64); ok {
+		if lo, ok := schema["minimum"].(float64); ok && n < lo {
+			return []string{fmt.Sprintf("%s = %v, want at least %v", path, n, lo)}
+		}
+		if hi, ok := schema["maximum"].(float64); ok && n > hi {
+			return []string{fmt.Sprintf("%s = %v, want at most %v", path, n, hi)}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, s := range anyOf {
+			if len(validate(root, s.(map[string]any), v, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s matches none of anyOf", path)}
+	}
+
+	var problems []string
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range schema["required"].([]any) {
+			if _, ok := v[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is missing", path, name))
+			}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for name, value := range v {
+			if s, ok := props[name]; ok {
+				problems = append(problems, validate(root, s.(map[string]any), value, path+"."+name)...)
+			} else if schema["additionalProperties"] == false {
+				problems = append(problems, fmt.Sprintf("%s.%s is not allowed", path, name))
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, value := range v {
+				problems = append(problems, validate(root, items, value, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return problems
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+func TestJSONEnvelope_MatchesSchema(t *testing.T) {
+	schema := loadEnvelopeSchema(t)
+	page := &grokipedia.Page{
+		Slug:    "go",
+		Title:   "Go",
+		Content: "## History\n\nSee [Rust](/page/Rust) and [the site](https://go.dev).\n\n### Early\n\nText.\n",
+		Extra:   json.RawMessage(`{"newField":{"a":1}}`),
+	}
+	var bad any
+	json.Unmarshal([]byte(`{"schema_version":2,"command":"search","results":[{"slug":1}]}`), &bad)
+	if len(validate(schema, schema, bad, "$")) == 0 {
+		t.Fatal("validate() accepts an invalid envelope")
+	}
+
+	search := command.Request{Command: "search", Query: "go", Paged: true, Limit: 10, Offset: 20}
+	pageRequest := command.Request{Command: "page"}
+
+	tests := []struct {
+		name    string
+		request command.Request
+		format  func(f *JSONFormatter) (string, error)
+	}{
+		{"search", search, func(f *JSONFormatter) (string, error) {
+			return f.FormatSearch([]grokipedia.SearchResult{
+				{Slug: "go", Title: "Go", Snippet: "A language", RelevanceScore: 1.5, ViewCount: 12, SnippetHighlights: []string{"language"}},
+			})
+		}},
+		{"no results", search, func(f *JSONFormatter) (string, error) { return f.NoResults(), nil }},
+		{"search --all", command.Request{Command: "search", Query: "go", Paged: true}, func(f *JSONFormatter) (string, error) {
+			return f.FormatSearch([]grokipedia.SearchResult{{Slug: "go"}})
+		}},
+		{"page", pageRequest, func(f *JSONFormatter) (string, error) { return f.FormatPage(page) }},
+		{"pages", pageRequest, func(f *JSONFormatter) (string, error) {
+			return f.FormatPages([]*grokipedia.Page{page, {Slug: "empty"}})
+		}},
+		{"page toc", pageRequest, func(f *JSONFormatter) (string, error) { return f.FormatTOC(page, page.Sections()) }},
+		{"links", command.Request{Command: "links"}, func(f *JSONFormatter) (string, error) {
+			return f.FormatLinks(page.Links(grokipedia.DefaultBaseURL))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &JSONFormatter{Envelope: true}
+			f.SetRequest(tt.request)
+			out, err := tt.format(f)
+			if err != nil {
+				t.Fatalf("format error = %v", err)
+			}
+			var v any
+			if err := json.Unmarshal([]byte(out), &v); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, out)
+			}
+			for _, p := range validate(schema, schema, v, "$") {
+				t.Error(p)
+			}
+		})
+	}
+}
+
+func TestJSONEnvelope(t *testing.T) {
+	f := &JSONFormatter{Envelope: true, FetchedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("", 3600))}
+	f.SetRequest(command.Request{Command: "search", Query: "go", Paged: true, Limit: 2})
+	got, err := f.FormatSearch([]grokipedia.SearchResult{{Slug: "go", Title: "Go", ViewCount: 3}})
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	want := `{
+  "schema_version": 1,
+  "command": "search",
+  "query": "go",
+  "limit": 2,
+  "offset": 0,
+  "count": 1,
+  "results": [
+    {
+      "slug": "go",
+      "title": "Go",
+      "snippet": "",
+      "relevance_score": 0,
+      "view_count": "3",
+      "title_highlights": null,
+      "snippet_highlights": null
+    }
+  ],
+  "fetched_at": "2025-03-01T11:00:00Z"
+}`
+	if got != want {
+		t.Errorf("FormatSearch() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Fields are selected from the results, and queries see the envelope.
+	f.Fields = []string{"slug"}
+	f.Query, _ = ParseQuery(".count, .results")
+	got, err = f.FormatSearch([]grokipedia.SearchResult{{Slug: "go"}, {Slug: "rust"}})
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	if want := "2\n[{\"slug\":\"go\"},{\"slug\":\"rust\"}]\n"; got != want {
+		t.Errorf("FormatSearch() with fields and query = %q, want %q", got, want)
+	}
+
+	// A missing page is no result, not a null one.
+	f = &JSONFormatter{Envelope: true}
+	f.SetRequest(command.Request{Command: "page"})
+	f.Query, _ = ParseQuery("{count, results, query, limit, offset}")
+	if got, _ := f.FormatPage(nil); got != "{\"count\":0,\"results\":[],\"query\":null,\"limit\":null,\"offset\":null}\n" {
+		t.Errorf("FormatPage(nil) = %q", got)
+	}
+}
+
+// TestJSONEnvelope_SchemaFields checks that the schema and the envelope
+// list the same fields, so that neither changes without the other.
+func TestJSONEnvelope_SchemaFields(t *testing.T) {
+	schema := loadEnvelopeSchema(t)
+	var fields []string
+	typ := reflect.TypeFor[envelopeJSON]()
+	for i := range typ.NumField() {
+		fields = append(fields, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+	}
+	var required []string
+	for _, name := range schema["required"].([]any) {
+		required = append(required, name.(string))
+	}
+	if !slices.Equal(fields, required) {
+		t.Errorf("envelope fields = %v, schema requires %v", fields, required)
+	}
+	if v := schema["properties"].(map[string]any)["schema_version"].(map[string]any)["const"]; v != float64(EnvelopeSchemaVersion) {
+		t.Errorf("schema_version in schema = %v, want %d", v, EnvelopeSchemaVersion)
+	}
+}
@@ -0,0 +1,97 @@
+package formatter
+
+import (
+	_ "embed"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"grokir/internal/epub"
+	"grokir/internal/grokipedia"
+)
+
+//go:embed epub.css
+var epubStyle string
+
+// EPUBFormatter assembles pages into an EPUB book, one chapter per page.
+type EPUBFormatter struct {
+	// BaseURL is the Grokipedia site that source links and links to
+	// pages outside the book point to.
+	BaseURL string
+	// Title is the book title. When empty, it is made from the page
+	// titles.
+	Title string
+	// FetchedAt is recorded as the book's modification time.
+	FetchedAt time.Time
+	// Aliases maps other slugs that name a page of the book, such as
+	// the ones requested, to the page's canonical slug.
+	Aliases map[string]string
+}
+
+func NewEPUB() *EPUBFormatter {
+	return &EPUBFormatter{BaseURL: grokipedia.DefaultBaseURL}
+}
+
+// WriteBook writes pages to w as an EPUB book. Links between the pages
+// lead to their chapters.
+func (f *EPUBFormatter) WriteBook(w io.Writer, pages []*grokipedia.Page) error {
+	return f.Book(pages).Write(w)
+}
+
+// Book returns the EPUB book for pages.
+func (f *EPUBFormatter) Book(pages []*grokipedia.Page) *epub.Book {
+	files := make(map[string]string)
+	for i, p := range pages {
+		files[p.Slug] = epub.ChapterFile(i)
+	}
+	for alias, slug := range f.Aliases {
+		if file, ok := files[slug]; ok {
+			files[alias] = file
+		}
+	}
+	hf := &HTMLFormatter{BaseURL: f.BaseURL, Files: files}
+
+	book := &epub.Book{
+		Title:      f.Title,
+		Creator:    "Grokipedia",
+		Source:     f.BaseURL,
+		Modified:   f.FetchedAt,
+		Stylesheet: epubStyle,
+	}
+	if book.Title == "" {
+		book.Title = bookTitle(pages)
+	}
+	for _, p := range pages {
+		r := newHTMLRenderer(hf.linkResolver(p.Slug, "", nil), "")
+		r.imageLinks = true
+		var body strings.Builder
+		hf.article(&body, r, p, "")
+
+		var headings []epub.Heading
+		for _, h := range r.headings {
+			headings = append(headings, epub.Heading{Level: h.level, Title: h.text, ID: h.id})
+		}
+		book.Chapters = append(book.Chapters, epub.Chapter{Title: p.Title, Body: body.String(), Headings: headings})
+	}
+	return book
+}
+
+// bookTitle names a book after its first few pages.
+func bookTitle(pages []*grokipedia.Page) string {
+	const shown = 3
+	var titles []string
+	for _, p := range pages[:min(len(pages), shown)] {
+		titles = append(titles, p.Title)
+	}
+	switch {
+	case len(pages) == 0:
+		return "Grokipedia"
+	case len(pages) > shown:
+		return strings.Join(titles, ", ") + " and " + strconv.Itoa(len(pages)-shown) + " more"
+	case len(pages) == 1:
+		return titles[0]
+	default:
+		return strings.Join(titles[:len(titles)-1], ", ") + " and " + titles[len(titles)-1]
+	}
+}
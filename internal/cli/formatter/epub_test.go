@@ -0,0 +1,79 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"grokir/internal/grokipedia"
+)
+
+func TestEPUBFormatter_Book(t *testing.T) {
+	pages := []*grokipedia.Page{
+		{Title: "Go", Slug: "Go", Content: "## History\n\nSee [Rust](/page/Rust), [its design](/page/rust#design) and ![logo](https://example.com/go.png)."},
+		{Title: "Rust", Slug: "Rust", Content: "## Design\n\nSee [Go](https://grokipedia.com/page/Go#history), [C](/page/C) and [below](#design)."},
+	}
+	f := NewEPUB()
+	f.Aliases = map[string]string{"rust": "Rust"}
+	book := f.Book(pages)
+
+	if book.Title != "Go and Rust" {
+		t.Errorf("Title = %q", book.Title)
+	}
+	if len(book.Chapters) != 2 {
+		t.Fatalf("got %d chapters, want 2", len(book.Chapters))
+	}
+	for _, ch := range book.Chapters {
+		checkWellFormed(t, "<body>"+ch.Body+"</body>")
+	}
+
+	goCh, rust := book.Chapters[0], book.Chapters[1]
+	for _, want := range []string{
+		`<h1>Go</h1>`,
+		`<h2 id="history">History</h2>`,
+		`<a href="chapter-002.xhtml">Rust</a>`,
+		`<a href="chapter-002.xhtml#design">its design</a>`,
+		`<a href="https://example.com/go.png">logo</a>`,
+	} {
+		if !strings.Contains(goCh.Body, want) {
+			t.Errorf("Go chapter missing %q in:\n%s", want, goCh.Body)
+		}
+	}
+	for _, want := range []string{
+		`<a href="chapter-001.xhtml#history">Go</a>`,
+		`<a href="https://grokipedia.com/page/C">C</a>`,
+		`<a href="#design">below</a>`,
+	} {
+		if !strings.Contains(rust.Body, want) {
+			t.Errorf("Rust chapter missing %q in:\n%s", want, rust.Body)
+		}
+	}
+	if len(goCh.Headings) != 1 || goCh.Headings[0].ID != "history" || goCh.Headings[0].Level != 2 {
+		t.Errorf("Go headings = %+v", goCh.Headings)
+	}
+}
+
+func TestEPUBFormatter_UnsafeURLs(t *testing.T) {
+	pages := []*grokipedia.Page{{Title: "Go", Slug: "Go", Content: "[x](javascript:alert(1)) and ![y](javascript:alert(2))"}}
+	body := NewEPUB().Book(pages).Chapters[0].Body
+	if strings.Contains(body, "javascript:") || !strings.Contains(body, "<p>x and y</p>") {
+		t.Errorf("chapter body = %s, want the links as plain text", body)
+	}
+}
+
+func TestBookTitle(t *testing.T) {
+	page := func(title string) *grokipedia.Page { return &grokipedia.Page{Title: title} }
+	tests := []struct {
+		pages []*grokipedia.Page
+		want  string
+	}{
+		{nil, "Grokipedia"},
+		{[]*grokipedia.Page{page("Go")}, "Go"},
+		{[]*grokipedia.Page{page("Go"), page("Rust"), page("C")}, "Go, Rust and C"},
+		{[]*grokipedia.Page{page("Go"), page("Rust"), page("C"), page("Zig"), page("D")}, "Go, Rust, C and 2 more"},
+	}
+	for _, tt := range tests {
+		if got := bookTitle(tt.pages); got != tt.want {
+			t.Errorf("bookTitle(%d pages) = %q, want %q", len(tt.pages), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package formatter
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
+)
+
+// Error codes of machine-readable error output, as returned by
+// ErrorCode.
+const (
+	CodeUsage       = "usage"
+	CodeNotFound    = "not_found"
+	CodeNotCached   = "not_cached"
+	CodeRateLimited = "rate_limited"
+	CodeUnavailable = "unavailable"
+	CodeAPIError    = "api_error"
+	CodeTimeout     = "timeout"
+	CodeInterrupted = "interrupted"
+	CodeError       = "error"
+)
+
+// ErrorCode classifies err by the client error types it wraps.
+func ErrorCode(err error) string {
+	var cmdErr *command.Error
+	var apiErr *grokipedia.APIError
+	switch {
+	case errors.As(err, &cmdErr) && cmdErr.IsUsage():
+		return CodeUsage
+	case errors.Is(err, httpcache.ErrNotCached):
+		return CodeNotCached
+	case errors.Is(err, grokipedia.ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, grokipedia.ErrRateLimited):
+		return CodeRateLimited
+	case errors.Is(err, grokipedia.ErrUnavailable):
+		return CodeUnavailable
+	case errors.As(err, &apiErr):
+		return CodeAPIError
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeInterrupted
+	}
+	return CodeError
+}
+
+// errorJSON is the object reporting a failure.
+type errorJSON struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		// Slug and Status describe a failed API request.
+		Slug   string `json:"slug,omitempty"`
+		Status int    `json:"status,omitempty"`
+	} `json:"error"`
+}
+
+// FormatErrorJSON renders err as a line of JSON holding an "error"
+// object with its ErrorCode and message, and with the HTTP status when
+// it wraps an API error. slug is the page the error concerns, if known;
+// otherwise that of a page request that failed is used.
+func FormatErrorJSON(err error, slug string) string {
+	var v errorJSON
+	v.Error.Code = ErrorCode(err)
+	v.Error.Message = err.Error()
+	v.Error.Slug = slug
+	var apiErr *grokipedia.APIError
+	if errors.As(err, &apiErr) {
+		v.Error.Slug = cmp.Or(slug, apiErr.Slug)
+		v.Error.Status = apiErr.StatusCode
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package formatter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
+)
+
+func TestErrorCode(t *testing.T) {
+	notFound := &grokipedia.APIError{StatusCode: http.StatusNotFound, Endpoint: "/api/page", Slug: "go"}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{command.NewUsageError("missing search query"), CodeUsage},
+		{command.NewRuntimeError("page retrieval error: %w", notFound), CodeNotFound},
+		{command.NewRuntimeError("%w: page %q has not been cached", httpcache.ErrNotCached, "go"), CodeNotCached},
+		{&grokipedia.APIError{StatusCode: http.StatusTooManyRequests}, CodeRateLimited},
+		{fmt.Errorf("search failed: %w", &grokipedia.APIError{StatusCode: http.StatusBadGateway}), CodeUnavailable},
+		{&grokipedia.APIError{StatusCode: http.StatusBadRequest}, CodeAPIError},
+		{fmt.Errorf("request: %w", context.DeadlineExceeded), CodeTimeout},
+		{&command.Error{Message: "interrupted", Err: context.Canceled}, CodeInterrupted},
+		{errors.New("unknown command: x"), CodeError},
+	}
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFormatErrorJSON(t *testing.T) {
+	err := command.NewRuntimeError("page retrieval error: %w",
+		fmt.Errorf("page not found: a&b: %w", &grokipedia.APIError{StatusCode: http.StatusNotFound, Endpoint: "/api/page", Slug: "a&b"}))
+	want := `{"error":{"code":"not_found","message":"page retrieval error: page not found: a&b: 404 Not Found from /api/page","slug":"a&b","status":404}}` + "\n"
+	if got := FormatErrorJSON(err, ""); got != want {
+		t.Errorf("FormatErrorJSON() = %s, want %s", got, want)
+	}
+
+	want = `{"error":{"code":"unavailable","message":"dial: service unavailable","slug":"go"}}` + "\n"
+	if got := FormatErrorJSON(fmt.Errorf("dial: %w", grokipedia.ErrUnavailable), "go"); got != want {
+		t.Errorf("FormatErrorJSON() with slug = %s, want %s", got, want)
+	}
+
+	want = `{"error":{"code":"usage","message":"missing page slug"}}` + "\n"
+	if got := FormatErrorJSON(command.NewUsageError("missing page slug"), ""); got != want {
+		t.Errorf("FormatErrorJSON() for a usage error = %s, want %s", got, want)
+	}
+}
@@ -2,22 +2,52 @@ package formatter
 
 import "grokir/internal/cli/command"
 
-// NewSearchFormatter returns a SearchFormatter for the given output mode.
-func NewSearchFormatter(mode command.OutputMode) command.SearchFormatter {
-	switch mode {
-	case command.OutputJSON:
-		return NewJSON()
-	default:
-		return NewText()
+// NewSearchFormatter returns a SearchFormatter for the runtime's output
+// format.
+func NewSearchFormatter(rt command.Runtime) (command.SearchFormatter, error) {
+	f, err := newFor(rt, command.ResultSearch)
+	if err != nil {
+		return nil, err
 	}
+	return f.(command.SearchFormatter), nil
 }
 
-// NewPageFormatter returns a PageFormatter for the given output mode.
-func NewPageFormatter(mode command.OutputMode) command.PageFormatter {
-	switch mode {
-	case command.OutputJSON:
-		return NewJSON()
-	default:
-		return NewText()
+// NewPageFormatter returns a PageFormatter for the runtime's output
+// format.
+func NewPageFormatter(rt command.Runtime) (command.PageFormatter, error) {
+	f, err := newFor(rt, command.ResultPage)
+	if err != nil {
+		return nil, err
 	}
+	return f.(command.PageFormatter), nil
+}
+
+// NewLinksFormatter returns a LinksFormatter for the runtime's output
+// format.
+func NewLinksFormatter(rt command.Runtime) (command.LinksFormatter, error) {
+	f, err := newFor(rt, command.ResultLinks)
+	if err != nil {
+		return nil, err
+	}
+	return f.(command.LinksFormatter), nil
+}
+
+func newTextFor(rt command.Runtime) *TextFormatter {
+	return &TextFormatter{Color: rt.Color, Width: rt.Width}
+}
+
+func newHTMLFor(rt command.Runtime) *HTMLFormatter {
+	f := NewHTML()
+	if rt.Client != nil {
+		f.BaseURL = rt.Client.BaseURL
+	}
+	return f
+}
+
+func newMarkdownFor(rt command.Runtime) *MarkdownFormatter {
+	f := NewMarkdown()
+	if rt.Client != nil {
+		f.BaseURL = rt.Client.BaseURL
+	}
+	return f
 }
@@ -0,0 +1,76 @@
+package formatter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+// ErrUnknownField is returned when the fields selected for output
+// include one the results do not have.
+var ErrUnknownField = errors.New("unknown field")
+
+// jsonFields are the fields --fields can select from each kind of
+// result in JSON output, in the order they are written.
+var jsonFields = map[command.ResultKind][]string{
+	command.ResultSearch: jsonNames(reflect.TypeFor[grokipedia.SearchResult]()),
+	command.ResultPage:   append(jsonNames(reflect.TypeFor[grokipedia.Page]()), "sections"),
+	command.ResultTOC:    {"title", "slug", "sections"},
+	command.ResultLinks:  jsonNames(reflect.TypeFor[grokipedia.PageLinks]()),
+}
+
+// JSONFields returns the fields that can be selected from results of
+// kind k in JSON, NDJSON and YAML output.
+func JSONFields(k command.ResultKind) []string {
+	return jsonFields[k]
+}
+
+// jsonNames returns the JSON names of the fields of the struct type t.
+func jsonNames(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		names = append(names, cmp.Or(name, f.Name))
+	}
+	return names
+}
+
+// checkFields returns an error wrapping ErrUnknownField for the first
+// of fields that is not in known.
+func checkFields(fields, known []string) error {
+	for _, name := range fields {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("%w %q (available: %s)", ErrUnknownField, name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// CheckFields returns an error wrapping ErrUnknownField if fields names
+// a field that none of the results of cmd have in format f, so that a
+// misspelled field is reported before anything is fetched.
+func CheckFields(cmd command.Command, f Format, fields []string) error {
+	fc, ok := cmd.(command.FormattedCommand)
+	if !ok || f.Fields == nil || len(fields) == 0 {
+		return nil
+	}
+	var known []string
+	for _, k := range fc.Results() {
+		for _, name := range f.Fields(k) {
+			if !slices.Contains(known, name) {
+				known = append(known, name)
+			}
+		}
+	}
+	return checkFields(fields, known)
+}
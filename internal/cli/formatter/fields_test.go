@@ -0,0 +1,56 @@
+package formatter
+
+import (
+	"errors"
+	"testing"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+func TestFields_UnknownRejected(t *testing.T) {
+	results := []grokipedia.SearchResult{{Slug: "go", Title: "Go"}}
+	search := fakeCommand{results: []command.ResultKind{command.ResultSearch}}
+	for _, name := range []string{"csv", "tsv", "json", "ndjson", "yaml"} {
+		t.Run(name, func(t *testing.T) {
+			format, ok := Lookup(name)
+			if !ok {
+				t.Fatalf("Lookup(%q) not found", name)
+			}
+			rt := command.Runtime{Output: command.OutputMode(name), Fields: []string{"slug", "views"}}
+			f, err := NewSearchFormatter(rt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.FormatSearch(results); !errors.Is(err, ErrUnknownField) {
+				t.Errorf("FormatSearch() error = %v, want ErrUnknownField", err)
+			}
+			if err := CheckFields(search, format, rt.Fields); !errors.Is(err, ErrUnknownField) {
+				t.Errorf("CheckFields() error = %v, want ErrUnknownField", err)
+			}
+
+			rt.Fields = []string{"title", "slug"}
+			f, err = NewSearchFormatter(rt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.FormatSearch(results); err != nil {
+				t.Errorf("FormatSearch() with known fields error = %v", err)
+			}
+			if err := CheckFields(search, format, rt.Fields); err != nil {
+				t.Errorf("CheckFields() with known fields error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckFields_ResultKinds(t *testing.T) {
+	jsonFormat, _ := Lookup("json")
+	page := fakeCommand{results: []command.ResultKind{command.ResultPage, command.ResultTOC}}
+	if err := CheckFields(page, jsonFormat, []string{"content", "sections", "linkedPages"}); err != nil {
+		t.Errorf("CheckFields(page) error = %v", err)
+	}
+	if err := CheckFields(page, jsonFormat, []string{"relevance_score"}); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("CheckFields(page, relevance_score) error = %v, want ErrUnknownField", err)
+	}
+}
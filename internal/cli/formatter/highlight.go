@@ -0,0 +1,110 @@
+package formatter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Markers used to emphasise highlighted terms when output is not styled
+// for a terminal. They match markdown strong emphasis.
+const (
+	markOpen  = "**"
+	markClose = "**"
+)
+
+// span is a byte range [start, end) of a string.
+type span struct {
+	start, end int
+}
+
+// highlightSpans returns the non-overlapping byte ranges of s that match
+// any of terms, ignoring case, in order of appearance. Longer terms win
+// when several match at the same position.
+func highlightSpans(s string, terms []string) []span {
+	var spans []span
+	for i := 0; i < len(s); {
+		best := 0
+		for _, t := range terms {
+			n := len(t)
+			if n == 0 || n <= best || i+n > len(s) {
+				continue
+			}
+			if strings.EqualFold(s[i:i+n], t) {
+				best = n
+			}
+		}
+		if best > 0 {
+			spans = append(spans, span{i, i + best})
+			i += best
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return spans
+}
+
+// markHighlights wraps every match of terms in s with open and close.
+func markHighlights(s string, terms []string, open, close string) string {
+	spans := highlightSpans(s, terms)
+	if len(spans) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(s[last:sp.start])
+		b.WriteString(open)
+		b.WriteString(s[sp.start:sp.end])
+		b.WriteString(close)
+		last = sp.end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// excerpt shortens s to about maxLen bytes like truncate, but when the
+// first match of terms would be cut off the window is moved to centre on
+// it instead, with "..." marking the text dropped on either side.
+func excerpt(s string, maxLen int, terms []string) string {
+	s = normalize(s)
+	if len(s) <= maxLen {
+		return s
+	}
+	spans := highlightSpans(s, terms)
+	if len(spans) == 0 || spans[0].end <= maxLen {
+		return truncate(s, maxLen)
+	}
+
+	first := spans[0]
+	if first.end-first.start >= maxLen {
+		// The match alone does not fit: show as much of it as does.
+		return "..." + truncate(s[first.start:], maxLen)
+	}
+	start := first.start - (maxLen-(first.end-first.start))/2
+	start = max(0, min(start, len(s)-maxLen))
+	end := min(len(s), start+maxLen)
+
+	// Prefer cutting at word boundaries, without losing the match.
+	if i := strings.IndexByte(s[start:first.start], ' '); start > 0 && i >= 0 {
+		start += i + 1
+	}
+	if i := strings.LastIndexByte(s[first.end:end], ' '); end < len(s) && i >= 0 {
+		end = first.end + i
+	}
+	for start > 0 && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	for end < len(s) && !utf8.RuneStart(s[end]) {
+		end--
+	}
+
+	out := strings.TrimSpace(s[start:end])
+	if start > 0 {
+		out = "..." + out
+	}
+	if end < len(s) {
+		out += "..."
+	}
+	return out
+}
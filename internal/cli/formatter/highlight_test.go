@@ -0,0 +1,83 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkHighlights(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		terms []string
+		want  string
+	}{
+		{"no terms", "Go programming", nil, "Go programming"},
+		{"case insensitive", "Go and go", []string{"GO"}, "**Go** and **go**"},
+		{"longest match wins", "gopher", []string{"go", "gopher"}, "**gopher**"},
+		{"multiple terms", "red fish blue fish", []string{"fish", "blue"}, "red **fish** **blue** **fish**"},
+		{"unicode", "Café culture", []string{"café"}, "**Café** culture"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markHighlights(tt.s, tt.terms, "**", "**"); got != tt.want {
+				t.Errorf("markHighlights() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("lorem ipsum ", 30)
+
+	t.Run("short text unchanged", func(t *testing.T) {
+		if got := excerpt("short\ntext", 50, []string{"text"}); got != "short text" {
+			t.Errorf("excerpt() = %q", got)
+		}
+	})
+
+	t.Run("early match truncates at end", func(t *testing.T) {
+		got := excerpt("needle "+long, 50, []string{"needle"})
+		if !strings.HasPrefix(got, "needle ") || !strings.HasSuffix(got, "...") {
+			t.Errorf("excerpt() = %q", got)
+		}
+	})
+
+	t.Run("late match is centered", func(t *testing.T) {
+		got := excerpt(long+"needle "+long, 50, []string{"needle"})
+		if !strings.Contains(got, "needle") {
+			t.Fatalf("excerpt() = %q, missing match", got)
+		}
+		if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "...") {
+			t.Errorf("excerpt() = %q, want ellipsis on both sides", got)
+		}
+		if len(got) > 50+6 {
+			t.Errorf("excerpt() length = %d, want at most %d", len(got), 56)
+		}
+		i := strings.Index(got, "needle")
+		if before, after := i, len(got)-i-len("needle"); before < 10 || after < 10 {
+			t.Errorf("excerpt() = %q, match not centered", got)
+		}
+	})
+
+	t.Run("match at the end", func(t *testing.T) {
+		got := excerpt(long+"needle", 50, []string{"needle"})
+		if !strings.HasPrefix(got, "...") || !strings.HasSuffix(got, "needle") {
+			t.Errorf("excerpt() = %q", got)
+		}
+	})
+
+	t.Run("match longer than maxLen", func(t *testing.T) {
+		match := strings.Repeat("needle", 20)
+		got := excerpt(long+match+" "+long, 50, []string{match})
+		if !strings.HasPrefix(got, "...needle") || !strings.HasSuffix(got, "...") || len(got) > 50+6 {
+			t.Errorf("excerpt() = %q", got)
+		}
+	})
+
+	t.Run("no match falls back to truncate", func(t *testing.T) {
+		if got, want := excerpt(long, 50, []string{"absent"}), truncate(long, 50); got != want {
+			t.Errorf("excerpt() = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,417 @@
+package formatter
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+	"grokir/internal/markdown"
+)
+
+// htmlStyle is the stylesheet embedded in every HTML document, so that
+// documents can be shared as single files.
+//
+//go:embed html.css
+var htmlStyle string
+
+// htmlHeading is a heading rendered by htmlRenderer, collected to build
+// the table of contents.
+type htmlHeading struct {
+	level int
+	text  string
+	id    string
+}
+
+// htmlRenderer renders parsed markdown as HTML. Its output is also
+// well-formed XHTML, so it can be used for e-book chapters.
+type htmlRenderer struct {
+	// resolve maps a link destination to the one written out.
+	resolve func(dest string) string
+	// idPrefix is prepended to heading ids, keeping them unique when
+	// several pages share a document.
+	idPrefix string
+	// imageLinks renders images as links to them, for documents that
+	// may not load remote resources.
+	imageLinks bool
+
+	b        strings.Builder
+	ids      map[string]bool
+	headings []htmlHeading
+}
+
+func newHTMLRenderer(resolve func(string) string, idPrefix string) *htmlRenderer {
+	if resolve == nil {
+		resolve = func(dest string) string { return dest }
+	}
+	return &htmlRenderer{resolve: resolve, idPrefix: idPrefix, ids: make(map[string]bool)}
+}
+
+// headingID returns a unique id for a heading, using the same anchors as
+// grokipedia.ParseSections.
+func (r *htmlRenderer) headingID(text string) string {
+	base := grokipedia.Anchor(text)
+	if base == "" {
+		base = "section"
+	}
+	id := base
+	for n := 1; r.ids[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	r.ids[id] = true
+	return r.idPrefix + id
+}
+
+func (r *htmlRenderer) blocks(bs []markdown.Block, tight bool) {
+	for _, b := range bs {
+		r.block(b, tight)
+	}
+}
+
+func (r *htmlRenderer) block(b markdown.Block, tight bool) {
+	switch b.Kind {
+	case markdown.Heading:
+		inlines := markdown.ParseInline(b.Text)
+		text := markdown.PlainText(inlines)
+		id := r.headingID(text)
+		r.headings = append(r.headings, htmlHeading{level: b.Level, text: text, id: id})
+		fmt.Fprintf(&r.b, "<h%d id=\"%s\">", b.Level, html.EscapeString(id))
+		r.inlines(inlines)
+		fmt.Fprintf(&r.b, "</h%d>\n", b.Level)
+	case markdown.Paragraph:
+		if tight {
+			r.inlines(markdown.ParseInline(b.Text))
+			return
+		}
+		r.b.WriteString("<p>")
+		r.inlines(markdown.ParseInline(b.Text))
+		r.b.WriteString("</p>\n")
+	case markdown.CodeBlock:
+		r.b.WriteString("<pre><code")
+		if b.Lang != "" {
+			fmt.Fprintf(&r.b, " class=\"language-%s\"", html.EscapeString(strings.Fields(b.Lang)[0]))
+		}
+		r.b.WriteString(">")
+		r.b.WriteString(html.EscapeString(b.Text))
+		r.b.WriteString("</code></pre>\n")
+	case markdown.Quote:
+		r.b.WriteString("<blockquote>\n")
+		r.blocks(b.Children, false)
+		r.b.WriteString("</blockquote>\n")
+	case markdown.List:
+		tag := "ul"
+		if b.Ordered {
+			tag = "ol"
+		}
+		r.b.WriteString("<" + tag)
+		if b.Ordered && b.Start != 1 {
+			fmt.Fprintf(&r.b, " start=\"%d\"", b.Start)
+		}
+		r.b.WriteString(">\n")
+		for _, item := range b.Items {
+			r.b.WriteString("<li>")
+			r.blocks(item, !b.Loose)
+			r.b.WriteString("</li>\n")
+		}
+		r.b.WriteString("</" + tag + ">\n")
+	case markdown.Table:
+		r.table(b)
+	case markdown.Rule:
+		r.b.WriteString("<hr/>\n")
+	}
+}
+
+func (r *htmlRenderer) table(b markdown.Block) {
+	cell := func(tag, text string, col int) {
+		r.b.WriteString("<" + tag)
+		if col < len(b.Align) {
+			switch b.Align[col] {
+			case markdown.AlignLeft:
+				r.b.WriteString(` style="text-align: left"`)
+			case markdown.AlignCenter:
+				r.b.WriteString(` style="text-align: center"`)
+			case markdown.AlignRight:
+				r.b.WriteString(` style="text-align: right"`)
+			}
+		}
+		r.b.WriteString(">")
+		r.inlines(markdown.ParseInline(text))
+		r.b.WriteString("</" + tag + ">")
+	}
+
+	r.b.WriteString("<table>\n<thead>\n<tr>")
+	for i, h := range b.Header {
+		cell("th", h, i)
+	}
+	r.b.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range b.Rows {
+		r.b.WriteString("<tr>")
+		for i, c := range row {
+			cell("td", c, i)
+		}
+		r.b.WriteString("</tr>\n")
+	}
+	r.b.WriteString("</tbody>\n</table>\n")
+}
+
+func (r *htmlRenderer) inlines(ins []markdown.Inline) {
+	for _, in := range ins {
+		switch in.Kind {
+		case markdown.Text:
+			r.b.WriteString(html.EscapeString(in.Text))
+		case markdown.Emphasis:
+			r.b.WriteString("<em>")
+			r.inlines(in.Children)
+			r.b.WriteString("</em>")
+		case markdown.Strong:
+			r.b.WriteString("<strong>")
+			r.inlines(in.Children)
+			r.b.WriteString("</strong>")
+		case markdown.Code:
+			r.b.WriteString("<code>" + html.EscapeString(in.Text) + "</code>")
+		case markdown.Link:
+			dest := r.resolve(in.URL)
+			if !safeURL(dest) {
+				r.inlines(in.Children)
+				continue
+			}
+			fmt.Fprintf(&r.b, "<a href=\"%s\">", html.EscapeString(dest))
+			r.inlines(in.Children)
+			r.b.WriteString("</a>")
+		case markdown.Image:
+			if !safeURL(in.URL) {
+				r.b.WriteString(html.EscapeString(in.Text))
+				continue
+			}
+			if r.imageLinks {
+				fmt.Fprintf(&r.b, "<a href=\"%s\">%s</a>",
+					html.EscapeString(in.URL), html.EscapeString(cmp.Or(in.Text, in.URL)))
+				continue
+			}
+			fmt.Fprintf(&r.b, "<img src=\"%s\" alt=\"%s\"/>",
+				html.EscapeString(in.URL), html.EscapeString(in.Text))
+		case markdown.LineBreak:
+			r.b.WriteString("<br/>\n")
+		}
+	}
+}
+
+// safeURL reports whether u may be written as a link or image source:
+// an http, https or mailto URL, a relative URL or a fragment. Anything
+// else, such as a javascript: URL, is rendered as plain text.
+func safeURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// toc renders the collected headings as nested lists.
+func (r *htmlRenderer) toc(b *strings.Builder) {
+	var levels []int
+	for _, h := range r.headings {
+		for len(levels) > 1 && h.level < levels[len(levels)-1] {
+			b.WriteString("</li>\n</ul>\n")
+			levels = levels[:len(levels)-1]
+		}
+		switch {
+		case len(levels) == 0 || h.level > levels[len(levels)-1]:
+			b.WriteString("<ul>\n")
+			levels = append(levels, h.level)
+		default:
+			b.WriteString("</li>\n")
+		}
+		fmt.Fprintf(b, "<li><a href=\"#%s\">%s</a>", html.EscapeString(h.id), html.EscapeString(h.text))
+	}
+	for range levels {
+		b.WriteString("</li>\n</ul>\n")
+	}
+}
+
+// HTMLFormatter formats pages as standalone HTML documents with an
+// embedded stylesheet and a table of contents.
+type HTMLFormatter struct {
+	// BaseURL is the Grokipedia site that source links and links to
+	// other pages point to.
+	BaseURL string
+	// Files maps the slugs of pages exported together to their file
+	// names. Links to those pages become relative file links.
+	Files map[string]string
+}
+
+func NewHTML() *HTMLFormatter {
+	return &HTMLFormatter{BaseURL: grokipedia.DefaultBaseURL}
+}
+
+func init() {
+	Register(Format{
+		Name:        "html",
+		Description: "standalone HTML documents",
+		New:         func(rt command.Runtime) any { return newHTMLFor(rt) },
+	})
+}
+
+// FormatPage renders a page as a complete HTML document.
+func (f *HTMLFormatter) FormatPage(page *grokipedia.Page) (string, error) {
+	return f.document(page.Title, []*grokipedia.Page{page}), nil
+}
+
+// FormatPages renders several pages as one HTML document, one article
+// per page. Links between the pages point into the document.
+func (f *HTMLFormatter) FormatPages(pages []*grokipedia.Page) (string, error) {
+	if len(pages) == 1 {
+		return f.FormatPage(pages[0])
+	}
+	var titles []string
+	for _, p := range pages {
+		titles = append(titles, p.Title)
+	}
+	return f.document(strings.Join(titles, ", "), pages), nil
+}
+
+// FormatSearch renders search results as an HTML document listing each
+// result with a link to its page and the matched terms marked.
+func (f *HTMLFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
+	var body strings.Builder
+	body.WriteString("<h1>Search results</h1>\n<ol class=\"results\">\n")
+	for _, r := range results {
+		fmt.Fprintf(&body, "<li><a href=\"%s\">%s</a>",
+			html.EscapeString(grokipedia.PageURL(f.BaseURL, r.Slug)), markHTML(r.Title, r.TitleHighlights))
+		fmt.Fprintf(&body, "<div class=\"meta\">relevance %.2f · %s views</div>", r.RelevanceScore, formatViews(r.ViewCount))
+		if r.Snippet != "" {
+			snippet := excerpt(r.Snippet, 300, r.SnippetHighlights)
+			fmt.Fprintf(&body, "<div>%s</div>", markHTML(snippet, r.SnippetHighlights))
+		}
+		body.WriteString("</li>\n")
+	}
+	body.WriteString("</ol>\n")
+	return f.wrap("Search results", "", body.String()), nil
+}
+
+// NoResults returns an HTML document saying that nothing was found.
+func (f *HTMLFormatter) NoResults() string {
+	return f.wrap("Search results", "", "<p>No results found. Try different keywords.</p>\n")
+}
+
+// markHTML escapes s for HTML and wraps the matches of terms in <mark>.
+func markHTML(s string, terms []string) string {
+	var b strings.Builder
+	last := 0
+	for _, sp := range highlightSpans(s, terms) {
+		b.WriteString(html.EscapeString(s[last:sp.start]))
+		b.WriteString("<mark>" + html.EscapeString(s[sp.start:sp.end]) + "</mark>")
+		last = sp.end
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return b.String()
+}
+
+func (f *HTMLFormatter) document(title string, pages []*grokipedia.Page) string {
+	// Within a document of several pages, each article's ids are
+	// prefixed with its slug.
+	single := len(pages) == 1
+	articleIDs := make(map[string]string)
+	if !single {
+		for _, p := range pages {
+			articleIDs[p.Slug] = grokipedia.Anchor(p.Slug)
+		}
+	}
+
+	var body, nav strings.Builder
+	for _, p := range pages {
+		prefix := ""
+		if !single {
+			prefix = articleIDs[p.Slug] + "--"
+		}
+		r := newHTMLRenderer(f.linkResolver(p.Slug, prefix, articleIDs), prefix)
+		f.article(&body, r, p, articleIDs[p.Slug])
+		if !single {
+			fmt.Fprintf(&nav, "<p class=\"toc-page\"><a href=\"#%s\">%s</a></p>\n",
+				html.EscapeString(articleIDs[p.Slug]), html.EscapeString(p.Title))
+		}
+		r.toc(&nav)
+	}
+
+	return f.wrap(title, nav.String(), body.String())
+}
+
+// wrap returns a complete HTML document with the given title, table of
+// contents and main content.
+func (f *HTMLFormatter) wrap(title, nav, body string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\"/>\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"/>\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	b.WriteString("<style>\n" + htmlStyle + "</style>\n</head>\n<body>\n")
+	if nav != "" {
+		b.WriteString("<nav class=\"toc\">\n<h2>Contents</h2>\n")
+		b.WriteString(nav)
+		b.WriteString("</nav>\n")
+	}
+	b.WriteString("<main>\n")
+	b.WriteString(body)
+	b.WriteString("</main>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// article renders one page, followed by a link to its source.
+func (f *HTMLFormatter) article(b *strings.Builder, r *htmlRenderer, page *grokipedia.Page, id string) {
+	b.WriteString("<article")
+	if id != "" {
+		fmt.Fprintf(b, " id=\"%s\"", html.EscapeString(id))
+	}
+	b.WriteString(">\n")
+	blocks := markdown.Parse(page.Content)
+	if len(blocks) > 0 && blocks[0].Kind == markdown.Heading && blocks[0].Level == 1 {
+		r.block(blocks[0], false)
+		blocks = blocks[1:]
+	} else {
+		fmt.Fprintf(&r.b, "<h1>%s</h1>\n", html.EscapeString(page.Title))
+	}
+	if page.Description != "" {
+		fmt.Fprintf(&r.b, "<p class=\"description\">%s</p>\n", html.EscapeString(normalize(page.Description)))
+	}
+	r.blocks(blocks, false)
+	b.WriteString(r.b.String())
+	source := grokipedia.PageURL(f.BaseURL, page.Slug)
+	fmt.Fprintf(b, "<footer class=\"source\">Source: <a href=\"%s\">%s on Grokipedia</a></footer>\n",
+		html.EscapeString(source), html.EscapeString(page.Title))
+	b.WriteString("</article>\n")
+}
+
+// linkResolver returns the link resolution for the page slug: links to a
+// section of the page itself stay in the document, links to other
+// articles of the document point to them, and everything else is
+// resolved as for export.
+func (f *HTMLFormatter) linkResolver(slug, idPrefix string, articles map[string]string) func(string) string {
+	return func(dest string) string {
+		if strings.HasPrefix(dest, "#") {
+			return "#" + idPrefix + dest[1:]
+		}
+		target, ok := grokipedia.SlugFromURL(f.BaseURL, dest)
+		if !ok {
+			return dest
+		}
+		_, fragment, _ := strings.Cut(dest, "#")
+		switch {
+		case target == slug && fragment != "":
+			return "#" + idPrefix + fragment
+		case articles[target] != "":
+			if fragment != "" {
+				return "#" + articles[target] + "--" + fragment
+			}
+			return "#" + articles[target]
+		}
+		return resolveLink(dest, f.BaseURL, f.Files)
+	}
+}
@@ -0,0 +1,164 @@
+package formatter
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"grokir/internal/grokipedia"
+)
+
+// checkWellFormed fails the test unless doc parses as XML, which the
+// HTML formatter promises so that its output can be reused for e-books.
+func checkWellFormed(t *testing.T, doc string) {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("output is not well-formed: %v\n%s", err, doc)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatPage(t *testing.T) {
+	page := &grokipedia.Page{
+		Title:       "Go",
+		Slug:        "Go",
+		Description: "A <simple> language.",
+		Content: "# Go\n\nBy [Rob *Pike*](/page/Rob_Pike) & friends, see [History](/page/Go#history) and [below](#design).\n\n" +
+			"## History\n\n- one\n- two\n  lines\n\n## Design\n\n```go\nif a < b {}\n```\n\n" +
+			"| A | B |\n|:-|-:|\n| `x` | ![img](a.png) |\n\n---\n\n> quoted\n",
+	}
+	f := &HTMLFormatter{BaseURL: "https://grokipedia.com", Files: map[string]string{"Rob_Pike": "Rob_Pike.html"}}
+
+	got, err := f.FormatPage(page)
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	checkWellFormed(t, got)
+
+	for _, want := range []string{
+		"<title>Go</title>",
+		"<style>",
+		`<nav class="toc">`,
+		`<li><a href="#history">History</a></li>`,
+		`<h1 id="go">Go</h1>` + "\n" + `<p class="description">A &lt;simple&gt; language.</p>`,
+		`<a href="Rob_Pike.html">Rob <em>Pike</em></a> &amp; friends`,
+		`<a href="#history">History</a> and <a href="#design">below</a>`,
+		`<h2 id="history">History</h2>`,
+		"<ul>\n<li>one</li>\n<li>two lines</li>\n</ul>",
+		`<pre><code class="language-go">if a &lt; b {}</code></pre>`,
+		`<th style="text-align: left">A</th><th style="text-align: right">B</th>`,
+		`<td style="text-align: left"><code>x</code></td>`,
+		`<img src="a.png" alt="img"/>`,
+		"<hr/>",
+		"<blockquote>\n<p>quoted</p>\n</blockquote>",
+		`<footer class="source">Source: <a href="https://grokipedia.com/page/Go">Go on Grokipedia</a></footer>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatPage() missing %q", want)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatPageAddsTitle(t *testing.T) {
+	got, err := NewHTML().FormatPage(&grokipedia.Page{Title: "A & B", Slug: "A", Content: "Text."})
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	checkWellFormed(t, got)
+	if !strings.Contains(got, "<h1>A &amp; B</h1>\n<p>Text.</p>") {
+		t.Errorf("FormatPage() = %s", got)
+	}
+	if strings.Contains(got, "<nav") {
+		t.Errorf("FormatPage() rendered a table of contents without headings")
+	}
+}
+
+func TestHTMLFormatter_UnsafeURLs(t *testing.T) {
+	page := &grokipedia.Page{
+		Title: "Go",
+		Slug:  "Go",
+		Content: "[x](javascript:alert(document.cookie)) [y](JavaScript:alert(1)) ![z](data:image/svg+xml,evil) " +
+			"[mail](mailto:a@example.com) [web](https://go.dev) [rel](other.html) [frag](#top)\n",
+	}
+	f := &HTMLFormatter{BaseURL: "https://grokipedia.com"}
+	got, err := f.FormatPage(page)
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	checkWellFormed(t, got)
+	for _, bad := range []string{"javascript:", "JavaScript:", "data:"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("output contains %q:\n%s", bad, got)
+		}
+	}
+	for _, want := range []string{
+		"<p>x y z ",
+		`<a href="mailto:a@example.com">mail</a>`,
+		`<a href="https://go.dev">web</a>`,
+		`<a href="other.html">rel</a>`,
+		`<a href="#top">frag</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatPages(t *testing.T) {
+	pages := []*grokipedia.Page{
+		{Title: "Go", Slug: "Go", Content: "## History\n\nSee [Rust](/page/Rust) and [its history](/page/Rust#history)."},
+		{Title: "Rust", Slug: "Rust", Content: "## History\n\nSee [Go](https://grokipedia.com/page/Go)."},
+	}
+	got, err := NewHTML().FormatPages(pages)
+	if err != nil {
+		t.Fatalf("FormatPages() error = %v", err)
+	}
+	checkWellFormed(t, got)
+
+	for _, want := range []string{
+		"<title>Go, Rust</title>",
+		`<article id="go">`,
+		`<article id="rust">`,
+		`<h2 id="go--history">History</h2>`,
+		`<h2 id="rust--history">History</h2>`,
+		`<a href="#rust">Rust</a> and <a href="#rust--history">its history</a>`,
+		`<a href="#go">Go</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatPages() missing %q", want)
+		}
+	}
+}
+
+func TestHTMLFormatter_FormatSearch(t *testing.T) {
+	results := []grokipedia.SearchResult{{
+		Slug:              "Go",
+		Title:             "Go <lang>",
+		Snippet:           "Go is fast & simple.",
+		TitleHighlights:   []string{"go"},
+		SnippetHighlights: []string{"fast"},
+		ViewCount:         1234,
+	}}
+	got, err := NewHTML().FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	checkWellFormed(t, got)
+	for _, want := range []string{
+		`<a href="https://grokipedia.com/page/Go"><mark>Go</mark> &lt;lang&gt;</a>`,
+		"1,234 views",
+		"Go is <mark>fast</mark> &amp; simple.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatSearch() missing %q in:\n%s", want, got)
+		}
+	}
+	checkWellFormed(t, NewHTML().NoResults())
+}
@@ -1,38 +1,306 @@
 package formatter
 
 import (
+	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"grokir/internal/cli/command"
 	"grokir/internal/grokipedia"
 )
 
 // JSONFormatter formats output as indented JSON.
-type JSONFormatter struct{}
+type JSONFormatter struct {
+	// Fields, when set, limits each record to these fields, in this
+	// order. A record is each element of an array, or the document
+	// itself when it is an object. Fields must be among JSONFields of
+	// the results; those a record lacks are null.
+	Fields []string
+	// Query, when set, is run on the document, and each of its outputs
+	// is written on a line of its own: strings as they are, other values
+	// as compact JSON. It runs after Fields are selected.
+	Query *Query
+	// Envelope wraps the output in an object describing the request,
+	// which holds the records in its results array. Its layout is
+	// described by the JSON Schema in envelope.schema.json.
+	Envelope bool
+	// FetchedAt is the time recorded in the envelope. It defaults to the
+	// time the output is formatted.
+	FetchedAt time.Time
+
+	request command.Request
+	err     error
+}
 
 func NewJSON() *JSONFormatter {
 	return &JSONFormatter{}
 }
 
-// FormatSearch renders search results as a JSON array.
-func (f *JSONFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
-	data, err := json.MarshalIndent(results, "", "  ")
+func init() {
+	Register(Format{
+		Name:        "json",
+		Description: "indented JSON",
+		New:         func(rt command.Runtime) any { return newJSONFor(rt) },
+		Fields:      JSONFields,
+		Query:       true,
+		JSON:        true,
+	})
+}
+
+func newJSONFor(rt command.Runtime) *JSONFormatter {
+	f := &JSONFormatter{Fields: rt.Fields, Envelope: rt.Envelope}
+	if rt.Query != "" {
+		f.Query, f.err = ParseQuery(rt.Query)
+	}
+	return f
+}
+
+// SetRequest records the request described by the envelope.
+func (f *JSONFormatter) SetRequest(r command.Request) {
+	f.request = r
+}
+
+// EnvelopeSchemaVersion is the schema_version of the JSON envelope. It
+// increases whenever a field of the envelope or its results is removed
+// or changes meaning.
+const EnvelopeSchemaVersion = 1
+
+// envelopeJSON is the object wrapping output when Envelope is set.
+type envelopeJSON struct {
+	SchemaVersion int     `json:"schema_version"`
+	Command       string  `json:"command"`
+	Query         *string `json:"query"`
+	Limit         *int    `json:"limit"`
+	Offset        *int    `json:"offset"`
+	Count         int     `json:"count"`
+	Results       any     `json:"results"`
+	FetchedAt     string  `json:"fetched_at"`
+}
+
+// records returns the output document for a list of count records.
+func (f *JSONFormatter) records(v any, count int) any {
+	if !f.Envelope {
+		return v
+	}
+	r := f.request
+	e := &envelopeJSON{
+		SchemaVersion: EnvelopeSchemaVersion,
+		Command:       r.Command,
+		Count:         count,
+		Results:       v,
+		FetchedAt:     cmp.Or(f.FetchedAt, time.Now()).UTC().Format(time.RFC3339),
+	}
+	if r.Query != "" {
+		e.Query = &r.Query
+	}
+	if r.Paged {
+		e.Offset = &r.Offset
+		if r.Limit > 0 {
+			e.Limit = &r.Limit
+		}
+	}
+	return e
+}
+
+// record returns the output document for a single record, or for none
+// when v is nil.
+func (f *JSONFormatter) record(v any) any {
+	if !f.Envelope {
+		return v
+	}
+	if v == nil {
+		return f.records([]any{}, 0)
+	}
+	return f.records([]any{v}, 1)
+}
+
+// marshal encodes the document v, holding results of kind k, as the
+// formatter's output.
+func (f *JSONFormatter) marshal(v any, k command.ResultKind) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if err := checkFields(f.Fields, JSONFields(k)); err != nil {
+		return "", err
+	}
+	fields := f.Fields
+	if e, ok := v.(*envelopeJSON); ok && len(fields) > 0 {
+		// The records of an envelope are its results.
+		data, err := json.Marshal(e.Results)
+		if err != nil {
+			return "", fmt.Errorf("JSON error: %w", err)
+		}
+		results, err := project(data, fields, nil)
+		if err != nil {
+			return "", err
+		}
+		e.Results = results[0]
+		fields = nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("JSON error: %w", err)
+	}
+	if len(fields) == 0 && f.Query == nil {
+		var b bytes.Buffer
+		if err := json.Indent(&b, data, "", "  "); err != nil {
+			return "", fmt.Errorf("JSON error: %w", err)
+		}
+		return b.String(), nil
+	}
+	values, err := project(data, fields, f.Query)
+	if err != nil {
+		return "", err
+	}
+	if f.Query != nil {
+		return queryLines(values), nil
+	}
+	data, err = json.MarshalIndent(values[0], "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("JSON error: %w", err)
 	}
 	return string(data), nil
 }
 
-// FormatPage renders a page as a JSON object.
-func (f *JSONFormatter) FormatPage(page *grokipedia.Page) (string, error) {
-	data, err := json.MarshalIndent(page, "", "  ")
+// project selects fields from the JSON document data and then runs the
+// query, if any, on it.
+func project(data []byte, fields []string, q *Query) ([]*jsonNode, error) {
+	n, err := parseJSON(string(data))
 	if err != nil {
-		return "", fmt.Errorf("JSON error: %w", err)
+		return nil, fmt.Errorf("JSON error: %w", err)
 	}
-	return string(data), nil
+	if len(fields) > 0 {
+		n = selectFields(n, fields)
+	}
+	if q == nil {
+		return []*jsonNode{n}, nil
+	}
+	values, err := q.run(n)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", q, err)
+	}
+	return values, nil
+}
+
+// selectFields limits each record in n to fields.
+func selectFields(n *jsonNode, fields []string) *jsonNode {
+	switch {
+	case n.isArray:
+		out := &jsonNode{isArray: true}
+		for _, c := range n.children {
+			out.children = append(out.children, selectFields(c, fields))
+		}
+		return out
+	case n.isObject:
+		out := &jsonNode{isObject: true}
+		for _, key := range fields {
+			v := n.field(key)
+			if v == nil {
+				v = &jsonNode{}
+			}
+			out.keys = append(out.keys, key)
+			out.children = append(out.children, v)
+		}
+		return out
+	}
+	return n
+}
+
+// queryLines writes each query output on a line of its own.
+func queryLines(values []*jsonNode) string {
+	var b bytes.Buffer
+	for _, v := range values {
+		if s, ok := v.value.(string); ok {
+			b.WriteString(s)
+		} else {
+			v.encode(&b)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// FormatSearch renders search results as a JSON array.
+func (f *JSONFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
+	return f.marshal(f.records(results, len(results)), command.ResultSearch)
+}
+
+// pageJSON is a page with its section tree.
+type pageJSON struct {
+	*grokipedia.Page
+	Sections []grokipedia.Section `json:"sections"`
+}
+
+// MarshalJSON encodes the page followed by its sections. It is needed
+// because the embedded Page's own MarshalJSON would otherwise be used
+// for the whole value.
+func (p pageJSON) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(p.Page)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := json.Marshal(p.Sections)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data[:len(data)-1], `,"sections":`...)
+	data = append(data, sections...)
+	return append(data, '}'), nil
+}
+
+func newPageJSON(page *grokipedia.Page) pageJSON {
+	sections := page.Sections()
+	if sections == nil {
+		sections = []grokipedia.Section{}
+	}
+	return pageJSON{Page: page, Sections: sections}
 }
 
-// NoResults returns an empty JSON array for when search returns no results.
+// FormatPage renders a page as a JSON object, including its section
+// tree.
+func (f *JSONFormatter) FormatPage(page *grokipedia.Page) (string, error) {
+	var v any
+	if page != nil {
+		v = newPageJSON(page)
+	}
+	return f.marshal(f.record(v), command.ResultPage)
+}
+
+// FormatPages renders several pages as a JSON array.
+func (f *JSONFormatter) FormatPages(pages []*grokipedia.Page) (string, error) {
+	out := make([]pageJSON, len(pages))
+	for i, page := range pages {
+		out[i] = newPageJSON(page)
+	}
+	return f.marshal(f.records(out, len(out)), command.ResultPage)
+}
+
+// FormatTOC renders a page's table of contents as a JSON object.
+func (f *JSONFormatter) FormatTOC(page *grokipedia.Page, sections []grokipedia.Section) (string, error) {
+	if sections == nil {
+		sections = []grokipedia.Section{}
+	}
+	toc := struct {
+		Title    string               `json:"title"`
+		Slug     string               `json:"slug"`
+		Sections []grokipedia.Section `json:"sections"`
+	}{page.Title, page.Slug, sections}
+	return f.marshal(f.record(toc), command.ResultTOC)
+}
+
+// FormatLinks renders the links of a page as a JSON object.
+func (f *JSONFormatter) FormatLinks(links grokipedia.PageLinks) (string, error) {
+	return f.marshal(f.record(links), command.ResultLinks)
+}
+
+// NoResults returns an empty JSON array, or an envelope holding one, for
+// when search returns no results; or what the query makes of it.
 func (f *JSONFormatter) NoResults() string {
-	return "[]"
+	out, err := f.marshal(f.records([]any{}, 0), command.ResultSearch)
+	if err != nil {
+		return ""
+	}
+	return out
 }
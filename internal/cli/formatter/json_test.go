@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"grokir/internal/cli/command"
 	"grokir/internal/grokipedia"
 )
 
@@ -116,6 +117,65 @@ func TestJSONFormatter_FormatPage(t *testing.T) {
 	}
 }
 
+func TestJSONFormatter_FormatPageSections(t *testing.T) {
+	page := &grokipedia.Page{
+		Title:   "Go",
+		Slug:    "go",
+		Content: "## History\n\n### Early years\n\n## Design\n",
+	}
+
+	for name, format := range map[string]func() (string, error){
+		"page": func() (string, error) { return NewJSON().FormatPage(page) },
+		"toc":  func() (string, error) { return NewJSON().FormatTOC(page, page.Sections()) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := format()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			var parsed struct {
+				Sections []grokipedia.Section `json:"sections"`
+			}
+			if err := json.Unmarshal([]byte(got), &parsed); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if len(parsed.Sections) != 2 || parsed.Sections[0].Anchor != "history" ||
+				len(parsed.Sections[0].Children) != 1 || parsed.Sections[0].Children[0].Level != 3 {
+				t.Errorf("sections = %+v", parsed.Sections)
+			}
+		})
+	}
+}
+
+func TestJSONFormatter_FormatPages(t *testing.T) {
+	f := NewJSON()
+
+	tests := []struct {
+		name  string
+		pages []*grokipedia.Page
+	}{
+		{name: "several pages", pages: []*grokipedia.Page{{Title: "One", Slug: "one"}, {Title: "Two", Slug: "two"}}},
+		{name: "nil slice", pages: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.FormatPages(tt.pages)
+			if err != nil {
+				t.Fatalf("FormatPages() error = %v", err)
+			}
+
+			var parsed []grokipedia.Page
+			if err := json.Unmarshal([]byte(got), &parsed); err != nil {
+				t.Fatalf("FormatPages() returned invalid JSON: %v", err)
+			}
+			if parsed == nil || len(parsed) != len(tt.pages) {
+				t.Errorf("FormatPages() returned %v, want %d pages", parsed, len(tt.pages))
+			}
+		})
+	}
+}
+
 func TestJSONFormatter_NoResults(t *testing.T) {
 	f := NewJSON()
 	got := f.NoResults()
@@ -124,3 +184,75 @@ func TestJSONFormatter_NoResults(t *testing.T) {
 		t.Errorf("NoResults() = %q, want %q", got, want)
 	}
 }
+
+func TestJSONFormatter_Fields(t *testing.T) {
+	results := []grokipedia.SearchResult{{Slug: "go", Title: "Go", ViewCount: 3}, {Slug: "rust", Title: "Rust"}}
+	f := &JSONFormatter{Fields: []string{"title", "slug", "title_highlights"}}
+	got, err := f.FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	want := `[
+  {
+    "title": "Go",
+    "slug": "go",
+    "title_highlights": null
+  },
+  {
+    "title": "Rust",
+    "slug": "rust",
+    "title_highlights": null
+  }
+]`
+	if got != want {
+		t.Errorf("FormatSearch() = %s, want %s", got, want)
+	}
+
+	// A single page is itself the record.
+	f.Fields = []string{"title", "slug", "citations"}
+	got, err = f.FormatPage(&grokipedia.Page{Slug: "go", Title: "Go"})
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	if want := "{\n  \"title\": \"Go\",\n  \"slug\": \"go\",\n  \"citations\": null\n}"; got != want {
+		t.Errorf("FormatPage() = %s, want %s", got, want)
+	}
+}
+
+func TestJSONFormatter_Query(t *testing.T) {
+	results := []grokipedia.SearchResult{{Slug: "go", Title: "Go", ViewCount: 3}, {Slug: "rust", Title: "Rust"}}
+	q, err := ParseQuery(".[] | .slug, {t: .title | length}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &JSONFormatter{Query: q}
+	got, err := f.FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	if want := "go\n{\"t\":2}\nrust\n{\"t\":4}\n"; got != want {
+		t.Errorf("FormatSearch() = %q, want %q", got, want)
+	}
+	if got := f.NoResults(); got != "" {
+		t.Errorf("NoResults() = %q, want no output", got)
+	}
+
+	// Fields are selected before the query runs.
+	f.Fields = []string{"slug"}
+	f.Query, _ = ParseQuery(".[0] | keys")
+	if got, _ := f.FormatSearch(results); got != "[\"slug\"]\n" {
+		t.Errorf("FormatSearch() with fields = %q", got)
+	}
+
+	f.Query, _ = ParseQuery(".results")
+	if _, err := f.FormatSearch(results); err == nil {
+		t.Error("FormatSearch() = nil error for a query that does not fit the output")
+	}
+}
+
+func TestNewJSONFor(t *testing.T) {
+	f := newJSONFor(command.Runtime{Fields: []string{"slug"}, Query: ".["})
+	if _, err := f.FormatSearch(nil); err == nil {
+		t.Error("FormatSearch() = nil error with an invalid query")
+	}
+}
@@ -0,0 +1,159 @@
+package formatter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+	"grokir/internal/markdown"
+)
+
+// MarkdownFormatter formats pages as Markdown documents, such as the
+// files written by export.
+type MarkdownFormatter struct {
+	// BaseURL is the Grokipedia site that source URLs and links to
+	// other pages point to.
+	BaseURL string
+	// FrontMatter starts each document with a YAML block describing
+	// the page.
+	FrontMatter bool
+	// FetchedAt is recorded in the front matter.
+	FetchedAt time.Time
+	// Files maps the slugs of pages exported together to their file
+	// names. Links to those pages become relative file links.
+	Files map[string]string
+}
+
+func NewMarkdown() *MarkdownFormatter {
+	return &MarkdownFormatter{BaseURL: grokipedia.DefaultBaseURL}
+}
+
+func init() {
+	Register(Format{
+		Name:        "markdown",
+		Description: "Markdown documents and lists",
+		New:         func(rt command.Runtime) any { return newMarkdownFor(rt) },
+	})
+}
+
+// FormatPage renders a page as Markdown, with a title heading when the
+// content does not start with one.
+func (f *MarkdownFormatter) FormatPage(page *grokipedia.Page) (string, error) {
+	var b strings.Builder
+	if f.FrontMatter {
+		f.writeFrontMatter(&b, page)
+	}
+
+	content := strings.TrimSpace(page.Content)
+	if !strings.HasPrefix(content, "# ") {
+		b.WriteString("# " + markdownEscape(page.Title) + "\n\n")
+	}
+	content = markdown.RewriteLinks(content, func(dest string) string {
+		return resolveLink(dest, f.BaseURL, f.Files)
+	})
+	if content != "" {
+		b.WriteString(content)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// FormatSearch renders search results as a numbered Markdown list of
+// links, with matched terms in bold.
+func (f *MarkdownFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
+	var b strings.Builder
+	for i, r := range results {
+		fmt.Fprintf(&b, "%d. [%s](%s) (%s views)\n", i+1,
+			markdownHighlight(r.Title, r.TitleHighlights),
+			grokipedia.PageURL(f.BaseURL, r.Slug), formatViews(r.ViewCount))
+		if r.Snippet != "" {
+			snippet := excerpt(r.Snippet, 200, r.SnippetHighlights)
+			fmt.Fprintf(&b, "   %s\n", markdownHighlight(snippet, r.SnippetHighlights))
+		}
+	}
+	return b.String(), nil
+}
+
+// NoResults returns a message for a search without results.
+func (f *MarkdownFormatter) NoResults() string {
+	return "_No results found._\n"
+}
+
+// FormatTOC renders a page's sections as a nested Markdown list of links
+// to their anchors.
+func (f *MarkdownFormatter) FormatTOC(page *grokipedia.Page, sections []grokipedia.Section) (string, error) {
+	var b strings.Builder
+	b.WriteString("# " + markdownEscape(page.Title) + "\n\n")
+	var walk func(sections []grokipedia.Section, depth int)
+	walk = func(sections []grokipedia.Section, depth int) {
+		for _, s := range sections {
+			fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", depth), markdownEscape(s.Title), s.Anchor)
+			walk(s.Children, depth+1)
+		}
+	}
+	walk(sections, 0)
+	return b.String(), nil
+}
+
+// markdownEscape escapes the characters of s that Markdown would treat
+// as inline markup.
+func markdownEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// markdownHighlight escapes s and puts the matched terms in bold.
+func markdownHighlight(s string, terms []string) string {
+	// The markers are control characters, so escaping leaves them be.
+	s = markdownEscape(markHighlights(s, terms, "\x00", "\x01"))
+	return strings.NewReplacer("\x00", markOpen, "\x01", markClose).Replace(s)
+}
+
+func (f *MarkdownFormatter) writeFrontMatter(b *strings.Builder, page *grokipedia.Page) {
+	b.WriteString("---\n")
+	fmt.Fprintf(b, "title: %s\n", yamlString(page.Title))
+	fmt.Fprintf(b, "slug: %s\n", yamlString(page.Slug))
+	if page.Description != "" {
+		fmt.Fprintf(b, "description: %s\n", yamlString(normalize(page.Description)))
+	}
+	fmt.Fprintf(b, "source: %s\n", yamlString(grokipedia.PageURL(f.BaseURL, page.Slug)))
+	if !f.FetchedAt.IsZero() {
+		fmt.Fprintf(b, "fetched_at: %s\n", f.FetchedAt.UTC().Format(time.RFC3339))
+	}
+	b.WriteString("---\n\n")
+}
+
+// yamlString quotes s as a YAML double-quoted scalar. Go's escapes are
+// a subset of YAML's, so strconv.Quote is enough.
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
+
+// resolveLink returns the destination to use for a link to dest in an
+// exported document. Links to pages listed in files become relative file
+// links; links to other pages become absolute URLs on baseURL. Anything
+// else is returned unchanged.
+func resolveLink(dest, baseURL string, files map[string]string) string {
+	slug, ok := grokipedia.SlugFromURL(baseURL, dest)
+	if !ok {
+		return dest
+	}
+	var fragment string
+	if u, err := url.Parse(dest); err == nil && u.Fragment != "" {
+		fragment = "#" + u.EscapedFragment()
+	}
+	if file, ok := files[slug]; ok {
+		return (&url.URL{Path: file}).String() + fragment
+	}
+	return grokipedia.PageURL(baseURL, slug) + fragment
+}
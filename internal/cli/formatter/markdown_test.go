@@ -0,0 +1,97 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"grokir/internal/grokipedia"
+)
+
+func TestMarkdownFormatter_FormatPage(t *testing.T) {
+	page := &grokipedia.Page{
+		Title:       "Go",
+		Slug:        "Go",
+		Description: "A \"simple\"\nlanguage.",
+		Content: "Designed by [Rob Pike](/page/Rob_Pike) at [Google](https://google.com).\n\n" +
+			"See [Rust](https://grokipedia.com/page/Rust#history) and [C](/page/C_(language)).\n",
+	}
+	f := &MarkdownFormatter{
+		BaseURL:     "https://grokipedia.com",
+		FrontMatter: true,
+		FetchedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+		Files:       map[string]string{"Rob_Pike": "Rob_Pike.md", "C_(language)": "C_(language).md"},
+	}
+
+	got, err := f.FormatPage(page)
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	want := "---\n" +
+		"title: \"Go\"\n" +
+		"slug: \"Go\"\n" +
+		"description: \"A \\\"simple\\\" language.\"\n" +
+		"source: \"https://grokipedia.com/page/Go\"\n" +
+		"fetched_at: 2025-01-02T02:04:05Z\n" +
+		"---\n\n" +
+		"# Go\n\n" +
+		"Designed by [Rob Pike](Rob_Pike.md) at [Google](https://google.com).\n\n" +
+		"See [Rust](https://grokipedia.com/page/Rust#history) and [C](C_%28language%29.md).\n"
+	if got != want {
+		t.Errorf("FormatPage() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarkdownFormatter_ExistingTitle(t *testing.T) {
+	got, err := NewMarkdown().FormatPage(&grokipedia.Page{Title: "Go", Content: "# Go\n\nText."})
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	if got != "# Go\n\nText.\n" {
+		t.Errorf("FormatPage() = %q", got)
+	}
+	if strings.Contains(got, "---") {
+		t.Errorf("FormatPage() wrote front matter without FrontMatter set")
+	}
+}
+
+func TestMarkdownFormatter_TitleEscaped(t *testing.T) {
+	got, err := NewMarkdown().FormatPage(&grokipedia.Page{Title: "C*_[draft]", Content: "Text."})
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	if want := "# C\\*\\_\\[draft\\]\n\nText.\n"; got != want {
+		t.Errorf("FormatPage() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownFormatter_FormatSearch(t *testing.T) {
+	results := []grokipedia.SearchResult{{
+		Slug:              "C_(language)",
+		Title:             "C [language]",
+		Snippet:           "C is *fast* and portable.",
+		SnippetHighlights: []string{"portable"},
+		ViewCount:         1234,
+	}}
+	got, err := NewMarkdown().FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	want := "1. [C \\[language\\]](https://grokipedia.com/page/C_%28language%29) (1,234 views)\n" +
+		"   C is \\*fast\\* and **portable**.\n"
+	if got != want {
+		t.Errorf("FormatSearch() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownFormatter_FormatTOC(t *testing.T) {
+	page := &grokipedia.Page{Title: "Go", Slug: "Go", Content: "## History\n\n### Early years\n\n## Design\n"}
+	got, err := NewMarkdown().FormatTOC(page, page.Sections())
+	if err != nil {
+		t.Fatalf("FormatTOC() error = %v", err)
+	}
+	want := "# Go\n\n- [History](#history)\n  - [Early years](#early-years)\n- [Design](#design)\n"
+	if got != want {
+		t.Errorf("FormatTOC() = %q, want %q", got, want)
+	}
+}
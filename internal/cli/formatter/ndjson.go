@@ -0,0 +1,162 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+// NDJSONFormatter formats output as newline-delimited JSON: one compact
+// object per line for each search result or page, so that output can be
+// streamed and processed line by line.
+type NDJSONFormatter struct {
+	// Fields and Query are applied to each line as they are by
+	// JSONFormatter to a whole document.
+	Fields []string
+	Query  *Query
+
+	err error
+}
+
+func NewNDJSON() *NDJSONFormatter {
+	return &NDJSONFormatter{}
+}
+
+func init() {
+	Register(Format{
+		Name:        "ndjson",
+		Description: "one compact JSON object per line, written as results arrive",
+		New: func(rt command.Runtime) any {
+			j := newJSONFor(rt)
+			return &NDJSONFormatter{Fields: j.Fields, Query: j.Query, err: j.err}
+		},
+		Fields: JSONFields,
+		Query:  true,
+		JSON:   true,
+	})
+}
+
+// FormatSearch renders each search result as a line of JSON.
+func (f *NDJSONFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
+	var b strings.Builder
+	for _, r := range results {
+		if err := f.writeRecord(&b, r, command.ResultSearch); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// FormatPage renders a page, including its section tree, as a line of
+// JSON.
+func (f *NDJSONFormatter) FormatPage(page *grokipedia.Page) (string, error) {
+	var v any
+	if page != nil {
+		v = newPageJSON(page)
+	}
+	var b strings.Builder
+	err := f.writeRecord(&b, v, command.ResultPage)
+	return b.String(), err
+}
+
+// FormatPages renders each page as a line of JSON.
+func (f *NDJSONFormatter) FormatPages(pages []*grokipedia.Page) (string, error) {
+	var b strings.Builder
+	for _, page := range pages {
+		if err := f.writeRecord(&b, newPageJSON(page), command.ResultPage); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// FormatTOC renders a page's table of contents as a line of JSON.
+func (f *NDJSONFormatter) FormatTOC(page *grokipedia.Page, sections []grokipedia.Section) (string, error) {
+	data, err := NewJSON().FormatTOC(page, sections)
+	return f.compactJSON(data, err, command.ResultTOC)
+}
+
+// FormatLinks renders the links of a page as a line of JSON.
+func (f *NDJSONFormatter) FormatLinks(links grokipedia.PageLinks) (string, error) {
+	data, err := NewJSON().FormatLinks(links)
+	return f.compactJSON(data, err, command.ResultLinks)
+}
+
+// NoResults returns no lines.
+func (f *NDJSONFormatter) NoResults() string {
+	return ""
+}
+
+// summaryJSON is the record that ends streamed output.
+type summaryJSON struct {
+	Summary struct {
+		Count  int    `json:"count"`
+		Failed int    `json:"failed"`
+		Error  string `json:"error,omitempty"`
+	} `json:"summary"`
+}
+
+// FormatSummary renders s as a line of JSON holding a "summary" object,
+// which tells it apart from the result records.
+func (f *NDJSONFormatter) FormatSummary(s command.Summary) (string, error) {
+	var v summaryJSON
+	v.Summary.Count = s.Count
+	v.Summary.Failed = s.Failed
+	if s.Err != nil {
+		v.Summary.Error = s.Err.Error()
+	}
+	var b strings.Builder
+	err := writeLine(&b, v)
+	return b.String(), err
+}
+
+// writeRecord writes v, a result of kind k, as a line of JSON, after
+// selecting Fields, or as the lines of the outputs of Query.
+func (f *NDJSONFormatter) writeRecord(b *strings.Builder, v any, k command.ResultKind) error {
+	if f.err != nil {
+		return f.err
+	}
+	if err := checkFields(f.Fields, JSONFields(k)); err != nil {
+		return err
+	}
+	if len(f.Fields) == 0 && f.Query == nil {
+		return writeLine(b, v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("JSON error: %w", err)
+	}
+	values, err := project(data, f.Fields, f.Query)
+	if err != nil {
+		return err
+	}
+	if f.Query != nil {
+		b.WriteString(queryLines(values))
+		return nil
+	}
+	return writeLine(b, values[0])
+}
+
+func writeLine(b *strings.Builder, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("JSON error: %w", err)
+	}
+	b.Write(data)
+	b.WriteByte('\n')
+	return nil
+}
+
+// compactJSON turns the indented JSON document data, a result of kind
+// k, into a single line.
+func (f *NDJSONFormatter) compactJSON(data string, err error, k command.ResultKind) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = f.writeRecord(&b, json.RawMessage(data), k)
+	return b.String(), err
+}
@@ -0,0 +1,99 @@
+package formatter
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+func TestNDJSONFormatter_FormatSearch(t *testing.T) {
+	results := []grokipedia.SearchResult{
+		{Slug: "one", Title: "One\nline", ViewCount: 5},
+		{Slug: "two", Title: "Two"},
+	}
+	got, err := NewNDJSON().FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != len(results) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(results), got)
+	}
+	for i, line := range lines {
+		var r grokipedia.SearchResult
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if r.Slug != results[i].Slug || r.Title != results[i].Title {
+			t.Errorf("line %d = %+v, want %+v", i, r, results[i])
+		}
+	}
+
+	// The output for several results is the output for each in turn,
+	// which is what allows it to be streamed.
+	first, _ := NewNDJSON().FormatSearch(results[:1])
+	second, _ := NewNDJSON().FormatSearch(results[1:])
+	if first+second != got {
+		t.Errorf("FormatSearch() of each result = %q, want %q", first+second, got)
+	}
+	if got := NewNDJSON().NoResults(); got != "" {
+		t.Errorf("NoResults() = %q, want no output", got)
+	}
+}
+
+func TestNDJSONFormatter_FormatPage(t *testing.T) {
+	got, err := NewNDJSON().FormatPage(&grokipedia.Page{Slug: "go", Title: "Go", Content: "## History\n\nText.\n"})
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	if strings.Count(got, "\n") != 1 || !strings.HasSuffix(got, "\n") {
+		t.Errorf("FormatPage() = %q, want a single line", got)
+	}
+	if !strings.Contains(got, `"sections":[{"title":"History"`) {
+		t.Errorf("FormatPage() = %s, want sections", got)
+	}
+}
+
+func TestNDJSONFormatter_FormatSummary(t *testing.T) {
+	tests := []struct {
+		summary command.Summary
+		want    string
+	}{
+		{command.Summary{Count: 3}, `{"summary":{"count":3,"failed":0}}` + "\n"},
+		{command.Summary{Count: 2, Failed: 1, Err: errors.New("1 of 3 pages failed")}, `{"summary":{"count":2,"failed":1,"error":"1 of 3 pages failed"}}` + "\n"},
+	}
+	for _, tt := range tests {
+		got, err := NewNDJSON().FormatSummary(tt.summary)
+		if err != nil {
+			t.Fatalf("FormatSummary() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("FormatSummary(%+v) = %q, want %q", tt.summary, got, tt.want)
+		}
+	}
+}
+
+func TestNDJSONFormatter_FieldsAndQuery(t *testing.T) {
+	results := []grokipedia.SearchResult{{Slug: "go", Title: "Go"}, {Slug: "rust", Title: "Rust"}}
+	f := &NDJSONFormatter{Fields: []string{"title", "slug"}}
+	got, err := f.FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	if want := "{\"title\":\"Go\",\"slug\":\"go\"}\n{\"title\":\"Rust\",\"slug\":\"rust\"}\n"; got != want {
+		t.Errorf("FormatSearch() = %q, want %q", got, want)
+	}
+
+	// The query runs on each record, and the summary is left alone.
+	f.Query, _ = ParseQuery(".slug")
+	if got, _ := f.FormatSearch(results); got != "go\nrust\n" {
+		t.Errorf("FormatSearch() with query = %q", got)
+	}
+	if got, _ := f.FormatSummary(command.Summary{Count: 2}); got != "{\"summary\":{\"count\":2,\"failed\":0}}\n" {
+		t.Errorf("FormatSummary() = %q", got)
+	}
+}
@@ -0,0 +1,148 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// jsonNode is a decoded JSON value that keeps the order of object keys,
+// so that output derived from it lists fields in the order the
+// formatters wrote them.
+type jsonNode struct {
+	isObject bool
+	isArray  bool
+	// value is the string, json.Number, bool or nil of a scalar.
+	value    any
+	keys     []string
+	children []*jsonNode
+}
+
+// parseJSON decodes the JSON document data.
+func parseJSON(data string) (*jsonNode, error) {
+	d := json.NewDecoder(strings.NewReader(data))
+	d.UseNumber()
+	n, err := decodeJSONNode(d)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return n, nil
+}
+
+func decodeJSONNode(d *json.Decoder) (*jsonNode, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{}
+	switch t := tok.(type) {
+	case json.Delim:
+		n.isObject = t == '{'
+		n.isArray = t == '['
+		for d.More() {
+			if n.isObject {
+				key, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			child, err := decodeJSONNode(d)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+	default:
+		n.value = t
+	}
+	return n, nil
+}
+
+func (n *jsonNode) empty() bool {
+	return (n.isObject || n.isArray) && len(n.children) == 0
+}
+
+// field returns the value of key in an object, or nil if it has none.
+func (n *jsonNode) field(key string) *jsonNode {
+	if i := slices.Index(n.keys, key); i >= 0 {
+		return n.children[i]
+	}
+	return nil
+}
+
+// kind names the type of the value, for error messages.
+func (n *jsonNode) kind() string {
+	switch n.value.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	switch {
+	case n.isObject:
+		return "object"
+	case n.isArray:
+		return "array"
+	}
+	return "null"
+}
+
+// MarshalJSON encodes the value compactly, keeping the order of keys.
+func (n *jsonNode) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := n.encode(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (n *jsonNode) encode(b *bytes.Buffer) error {
+	switch {
+	case n.isObject:
+		b.WriteByte('{')
+		for i, key := range n.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			k, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			b.Write(k)
+			b.WriteByte(':')
+			if err := n.children[i].encode(b); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case n.isArray:
+		b.WriteByte('[')
+		for i, child := range n.children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := child.encode(b); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	default:
+		data, err := json.Marshal(n.value)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+	}
+	return nil
+}
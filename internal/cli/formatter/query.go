@@ -0,0 +1,500 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Query is a parsed jq-style expression selecting values from JSON
+// output. It supports a small subset of jq:
+//
+//	.                identity
+//	.name ."name"    object field (null when missing)
+//	.[] .[n] .["n"]  every element or value, an array element, a field
+//	a | b            run b on every output of a
+//	a, b             the outputs of a, then those of b
+//	{name, k: a}     build an object
+//	length, keys     the length of a value, the sorted keys of an object
+//
+// Paths chain, as in .results[].slug.
+type Query struct {
+	text string
+	expr queryExpr
+}
+
+// ParseQuery parses a jq-style expression.
+func ParseQuery(text string) (*Query, error) {
+	p := &queryParser{src: text}
+	expr, err := p.pipe()
+	if err == nil {
+		p.space()
+		if p.pos < len(p.src) {
+			err = p.errorf("unexpected %q", p.src[p.pos:])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Query{text: text, expr: expr}, nil
+}
+
+func (q *Query) String() string {
+	return q.text
+}
+
+// run evaluates the query on n.
+func (q *Query) run(n *jsonNode) ([]*jsonNode, error) {
+	return q.expr.eval(n)
+}
+
+type queryExpr interface {
+	eval(in *jsonNode) ([]*jsonNode, error)
+}
+
+// pipeExpr runs right on every output of left.
+type pipeExpr struct {
+	left, right queryExpr
+}
+
+func (e pipeExpr) eval(in *jsonNode) ([]*jsonNode, error) {
+	values, err := e.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*jsonNode
+	for _, v := range values {
+		results, err := e.right.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, results...)
+	}
+	return out, nil
+}
+
+// commaExpr concatenates the outputs of its parts.
+type commaExpr []queryExpr
+
+func (e commaExpr) eval(in *jsonNode) ([]*jsonNode, error) {
+	var out []*jsonNode
+	for _, part := range e {
+		results, err := part.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, results...)
+	}
+	return out, nil
+}
+
+// pathStep is one step of a path: a field, an array index, or every
+// element.
+type pathStep struct {
+	field   string
+	index   int
+	isIndex bool
+	iterate bool
+}
+
+// pathExpr applies its steps in turn, starting from the input.
+type pathExpr []pathStep
+
+func (e pathExpr) eval(in *jsonNode) ([]*jsonNode, error) {
+	values := []*jsonNode{in}
+	for _, step := range e {
+		var next []*jsonNode
+		for _, v := range values {
+			results, err := step.apply(v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, results...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+func (s pathStep) apply(v *jsonNode) ([]*jsonNode, error) {
+	null := &jsonNode{}
+	switch {
+	case s.iterate:
+		if !v.isObject && !v.isArray {
+			return nil, fmt.Errorf("cannot iterate over %s", v.kind())
+		}
+		return v.children, nil
+	case s.isIndex:
+		if v.kind() == "null" {
+			return []*jsonNode{null}, nil
+		}
+		if !v.isArray {
+			return nil, fmt.Errorf("cannot index %s with a number", v.kind())
+		}
+		i := s.index
+		if i < 0 {
+			i += len(v.children)
+		}
+		if i < 0 || i >= len(v.children) {
+			return []*jsonNode{null}, nil
+		}
+		return []*jsonNode{v.children[i]}, nil
+	default:
+		if v.kind() == "null" {
+			return []*jsonNode{null}, nil
+		}
+		if !v.isObject {
+			return nil, fmt.Errorf("cannot index %s with %q", v.kind(), s.field)
+		}
+		if f := v.field(s.field); f != nil {
+			return []*jsonNode{f}, nil
+		}
+		return []*jsonNode{null}, nil
+	}
+}
+
+// objectExpr builds an object, one output for each combination of the
+// outputs of its values.
+type objectExpr struct {
+	keys   []string
+	values []queryExpr
+}
+
+func (e objectExpr) eval(in *jsonNode) ([]*jsonNode, error) {
+	objects := []*jsonNode{{isObject: true}}
+	for i, key := range e.keys {
+		values, err := e.values[i].eval(in)
+		if err != nil {
+			return nil, err
+		}
+		var next []*jsonNode
+		for _, obj := range objects {
+			for _, v := range values {
+				next = append(next, &jsonNode{
+					isObject: true,
+					keys:     append(slices.Clip(obj.keys), key),
+					children: append(slices.Clip(obj.children), v),
+				})
+			}
+		}
+		objects = next
+	}
+	return objects, nil
+}
+
+// builtinExpr is a named function of its input: length or keys.
+type builtinExpr string
+
+func (e builtinExpr) eval(in *jsonNode) ([]*jsonNode, error) {
+	switch e {
+	case "length":
+		var n int
+		switch v := in.value.(type) {
+		case string:
+			n = utf8.RuneCountInString(v)
+		case json.Number:
+			f, _ := v.Float64()
+			if f < 0 {
+				f = -f
+			}
+			return []*jsonNode{{value: json.Number(strconv.FormatFloat(f, 'f', -1, 64))}}, nil
+		case bool:
+			return nil, fmt.Errorf("boolean has no length")
+		default:
+			n = len(in.children)
+		}
+		return []*jsonNode{{value: json.Number(strconv.Itoa(n))}}, nil
+	default: // keys
+		if !in.isObject {
+			return nil, fmt.Errorf("%s has no keys", in.kind())
+		}
+		out := &jsonNode{isArray: true}
+		for _, k := range slices.Sorted(slices.Values(in.keys)) {
+			out.children = append(out.children, &jsonNode{value: k})
+		}
+		return []*jsonNode{out}, nil
+	}
+}
+
+// queryParser is a recursive descent parser for Query expressions.
+type queryParser struct {
+	src string
+	pos int
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid query at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) space() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\n\r", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept consumes c, after any space, if it comes next.
+func (p *queryParser) accept(c byte) bool {
+	p.space()
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) pipe() (queryExpr, error) {
+	left, err := p.comma()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept('|') {
+		right, err := p.comma()
+		if err != nil {
+			return nil, err
+		}
+		left = pipeExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) comma() (queryExpr, error) {
+	first, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	parts := commaExpr{first}
+	for p.accept(',') {
+		next, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, next)
+	}
+	if len(parts) == 1 {
+		return first, nil
+	}
+	return parts, nil
+}
+
+func (p *queryParser) term() (queryExpr, error) {
+	p.space()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of query")
+	}
+	var expr queryExpr
+	switch c := p.src[p.pos]; {
+	case c == '.':
+		p.pos++
+		if p.pos < len(p.src) && p.src[p.pos] == '.' {
+			return nil, p.errorf("recursive descent (..) is not supported")
+		}
+		path, err := p.path(true)
+		if err != nil {
+			return nil, err
+		}
+		expr = path
+	case c == '{':
+		p.pos++
+		obj, err := p.object()
+		if err != nil {
+			return nil, err
+		}
+		expr = obj
+	case c == '(':
+		p.pos++
+		inner, err := p.pipe()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(')') {
+			return nil, p.errorf("missing )")
+		}
+		expr = inner
+	case isIdentStart(c):
+		start := p.pos
+		name := p.ident()
+		if name != "length" && name != "keys" {
+			p.pos = start
+			return nil, p.errorf("unknown function %s", name)
+		}
+		expr = builtinExpr(name)
+	default:
+		return nil, p.errorf("unexpected %q", string(c))
+	}
+
+	// Paths may follow any term, as in (.a, .b)[0] or keys[0].
+	if p.pos < len(p.src) && (p.src[p.pos] == '[' || p.src[p.pos] == '.') {
+		if p.src[p.pos] == '.' {
+			p.pos++
+		}
+		path, err := p.path(false)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > 0 {
+			expr = pipeExpr{expr, path}
+		}
+	}
+	return expr, nil
+}
+
+// path parses the steps after a leading dot. When first is set, the dot
+// may stand alone for the identity.
+func (p *queryParser) path(first bool) (pathExpr, error) {
+	var steps pathExpr
+	afterDot := true
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case afterDot && isIdentStart(c):
+			steps = append(steps, pathStep{field: p.ident()})
+		case afterDot && c == '"':
+			s, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, pathStep{field: s})
+		case c == '[':
+			p.pos++
+			step, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+		case c == '.' && !afterDot:
+			p.pos++
+			afterDot = true
+			continue
+		default:
+			if afterDot && !(first && len(steps) == 0) {
+				return nil, p.errorf("expected a field name after .")
+			}
+			return steps, nil
+		}
+		afterDot = false
+	}
+	if afterDot && !(first && len(steps) == 0) {
+		return nil, p.errorf("expected a field name after .")
+	}
+	return steps, nil
+}
+
+// bracket parses what follows [ in a path: ], a number or a string.
+func (p *queryParser) bracket() (pathStep, error) {
+	p.space()
+	if p.accept(']') {
+		return pathStep{iterate: true}, nil
+	}
+	var step pathStep
+	if p.pos < len(p.src) && p.src[p.pos] == '"' {
+		s, err := p.str()
+		if err != nil {
+			return step, err
+		}
+		step.field = s
+	} else {
+		start := p.pos
+		if p.pos < len(p.src) && p.src[p.pos] == '-' {
+			p.pos++
+		}
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+		n, err := strconv.Atoi(p.src[start:p.pos])
+		if err != nil {
+			p.pos = start
+			return step, p.errorf("expected ], an index or a field name in brackets")
+		}
+		step.index, step.isIndex = n, true
+	}
+	if !p.accept(']') {
+		return step, p.errorf("missing ]")
+	}
+	return step, nil
+}
+
+// object parses the entries of an object after {.
+func (p *queryParser) object() (objectExpr, error) {
+	var obj objectExpr
+	if p.accept('}') {
+		return obj, nil
+	}
+	for {
+		p.space()
+		var key string
+		switch {
+		case p.pos < len(p.src) && p.src[p.pos] == '"':
+			s, err := p.str()
+			if err != nil {
+				return obj, err
+			}
+			key = s
+		case p.pos < len(p.src) && isIdentStart(p.src[p.pos]):
+			key = p.ident()
+		default:
+			return obj, p.errorf("expected a key in object")
+		}
+		var value queryExpr = pathExpr{{field: key}}
+		if p.accept(':') {
+			// Values stop at commas, which separate entries.
+			v, err := p.term()
+			if err != nil {
+				return obj, err
+			}
+			for p.accept('|') {
+				right, err := p.term()
+				if err != nil {
+					return obj, err
+				}
+				v = pipeExpr{v, right}
+			}
+			value = v
+		}
+		obj.keys = append(obj.keys, key)
+		obj.values = append(obj.values, value)
+		if p.accept('}') {
+			return obj, nil
+		}
+		if !p.accept(',') {
+			return obj, p.errorf("expected , or } in object")
+		}
+	}
+}
+
+func (p *queryParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) && (isIdentStart(p.src[p.pos]) || p.src[p.pos] >= '0' && p.src[p.pos] <= '9') {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// str parses a JSON string literal.
+func (p *queryParser) str() (string, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) && p.src[p.pos] != '"' {
+		if p.src[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		p.pos = start
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+	var s string
+	if err := json.Unmarshal([]byte(p.src[start:p.pos]), &s); err != nil {
+		p.pos = start
+		return "", p.errorf("invalid string: %v", err)
+	}
+	return s, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
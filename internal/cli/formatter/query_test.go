@@ -0,0 +1,79 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	const doc = `{"results":[{"slug":"go","title":"Go","views":10},{"slug":"rust","title":"Rust","views":2}],"count":2,"next":null}`
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{".", doc},
+		{".count", "2"},
+		{".missing", "null"},
+		{".next.slug", "null"},
+		{`."count"`, "2"},
+		{`.["count"]`, "2"},
+		{".results[].slug", `"go" "rust"`},
+		{".results | .[] | .title", `"Go" "Rust"`},
+		{".results[0].slug", `"go"`},
+		{".results[-1].slug", `"rust"`},
+		{".results[5]", "null"},
+		{".results[0] | keys", `["slug","title","views"]`},
+		{".results | length", "2"},
+		{".results[0].title | length", "2"},
+		{".count, .next", "2 null"},
+		{".results[] | {slug, v: .views}", `{"slug":"go","v":10} {"slug":"rust","v":2}`},
+		{"{n: (.results[] | .slug)}", `{"n":"go"} {"n":"rust"}`},
+		{`{"a b": .count}`, `{"a b":2}`},
+		{" .results[] | .slug ", `"go" "rust"`},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) error = %v", tt.query, err)
+			continue
+		}
+		n, err := parseJSON(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, err := q.run(n)
+		if err != nil {
+			t.Errorf("%q: run() error = %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, v := range values {
+			data, err := v.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, string(data))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q = %s, want %s", tt.query, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	for _, query := range []string{"", "results", ".[", ".a |", "{a", "..", ".a.", "length(", "(.a"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) = nil error, want one", query)
+		}
+	}
+	for _, query := range []string{".count[]", ".count.x", ".results.slug", ".flag | length", ".results | keys"} {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) error = %v", query, err)
+		}
+		n, _ := parseJSON(`{"count":2,"flag":true,"results":[{"slug":"go"}]}`)
+		if _, err := q.run(n); err == nil {
+			t.Errorf("%q: run() = nil error, want one", query)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package formatter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/config"
+)
+
+// Format is an output format selected with --format.
+type Format struct {
+	Name        string
+	Description string
+	// New returns the formatter for rt. The formatter implements the
+	// command formatter interfaces of the results the format supports.
+	// New is also called with a zero Runtime to find out which those
+	// are, so it must not depend on rt's fields being set.
+	New func(rt command.Runtime) any
+	// Fields returns the fields Runtime.Fields can select from results
+	// of a kind. It is nil for formats that do not honor Runtime.Fields.
+	Fields func(command.ResultKind) []string
+	// Query reports whether the format honors Runtime.Query.
+	Query bool
+	// JSON reports whether the format writes JSON, in which case errors
+	// are reported as JSON too.
+	JSON bool
+}
+
+var formats = make(map[string]Format)
+
+// Register adds an output format to the global registry, making its
+// name valid for --format and the format setting.
+func Register(f Format) {
+	formats[f.Name] = f
+	config.RegisterFormat(f.Name)
+}
+
+// Lookup retrieves a format by name from the registry.
+func Lookup(name string) (Format, bool) {
+	f, ok := formats[name]
+	return f, ok
+}
+
+// Formats returns every registered format, sorted by name.
+func Formats() []Format {
+	var out []Format
+	for _, f := range formats {
+		out = append(out, f)
+	}
+	slices.SortFunc(out, func(a, b Format) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// Supports reports whether the format can render results of every
+// given kind.
+func (f Format) Supports(kinds ...command.ResultKind) bool {
+	v := f.New(command.Runtime{})
+	for _, k := range kinds {
+		if !k.FormattedBy(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Kinds returns the kinds of results the format can render.
+func (f Format) Kinds() []command.ResultKind {
+	var kinds []command.ResultKind
+	for _, k := range []command.ResultKind{command.ResultSearch, command.ResultPage, command.ResultTOC, command.ResultLinks} {
+		if f.Supports(k) {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+// ForCommand returns the names of the formats that cmd can print its
+// results in. An OutputCommand supports the formats it lists; other
+// commands that do not print through a formatter support every format.
+func ForCommand(cmd command.Command) []string {
+	var names []string
+	for _, f := range Formats() {
+		switch c := cmd.(type) {
+		case command.FormattedCommand:
+			if !f.Supports(c.Results()...) {
+				continue
+			}
+		case command.OutputCommand:
+			if !slices.Contains(c.Outputs(), command.OutputMode(f.Name)) {
+				continue
+			}
+		}
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// CheckCommand returns an error if cmd cannot print its results in the
+// output format.
+func CheckCommand(cmd command.Command, output command.OutputMode) error {
+	f, ok := Lookup(string(output))
+	if !ok {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+	if names := ForCommand(cmd); !slices.Contains(names, f.Name) {
+		return fmt.Errorf("%s does not support --format %s (use one of: %s)",
+			cmd.Name(), f.Name, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// newFor returns the runtime's formatter for results of kind k.
+func newFor(rt command.Runtime, k command.ResultKind) (any, error) {
+	f, ok := Lookup(string(rt.Output))
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q", rt.Output)
+	}
+	v := f.New(rt)
+	if !k.FormattedBy(v) {
+		return nil, fmt.Errorf("format %s cannot render %s results", f.Name, k)
+	}
+	return v, nil
+}
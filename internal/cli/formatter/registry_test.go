@@ -0,0 +1,104 @@
+package formatter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/config"
+)
+
+type fakeCommand struct {
+	results []command.ResultKind
+}
+
+func (c fakeCommand) Name() string                        { return "fake" }
+func (c fakeCommand) Usage() string                       { return "grokir fake" }
+func (c fakeCommand) Run(command.Runtime, []string) error { return nil }
+func (c fakeCommand) Results() []command.ResultKind       { return c.results }
+
+type fakeOutputCommand struct {
+	outputs []command.OutputMode
+}
+
+func (c fakeOutputCommand) Name() string                        { return "fake" }
+func (c fakeOutputCommand) Usage() string                       { return "grokir fake" }
+func (c fakeOutputCommand) Run(command.Runtime, []string) error { return nil }
+func (c fakeOutputCommand) Outputs() []command.OutputMode       { return c.outputs }
+
+func TestRegistry(t *testing.T) {
+	var names []string
+	for _, f := range Formats() {
+		names = append(names, f.Name)
+	}
+	for _, want := range []string{"csv", "html", "json", "markdown", "ndjson", "text", "tsv", "yaml"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("Formats() = %v, missing %s", names, want)
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("Formats() = %v, want sorted", names)
+	}
+
+	key, _ := config.Lookup(config.Format)
+	if err := key.Validate("yaml"); err != nil {
+		t.Errorf("registered format rejected by config: %v", err)
+	}
+}
+
+func TestFormat_Supports(t *testing.T) {
+	tests := []struct {
+		format string
+		kind   command.ResultKind
+		want   bool
+	}{
+		{"text", command.ResultLinks, true},
+		{"json", command.ResultTOC, true},
+		{"markdown", command.ResultSearch, true},
+		{"markdown", command.ResultLinks, false},
+		{"html", command.ResultPage, true},
+		{"html", command.ResultLinks, false},
+	}
+	for _, tt := range tests {
+		f, ok := Lookup(tt.format)
+		if !ok {
+			t.Fatalf("Lookup(%q) not found", tt.format)
+		}
+		if got := f.Supports(tt.kind); got != tt.want {
+			t.Errorf("%s.Supports(%s) = %v, want %v", tt.format, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	links := fakeCommand{results: []command.ResultKind{command.ResultLinks}}
+	if err := CheckCommand(links, command.OutputJSON); err != nil {
+		t.Errorf("CheckCommand(links, json) error = %v", err)
+	}
+	err := CheckCommand(links, command.OutputHTML)
+	if err == nil || !strings.Contains(err.Error(), "use one of: json, ndjson, text, yaml") {
+		t.Errorf("CheckCommand(links, html) error = %v", err)
+	}
+	if err := CheckCommand(links, "nope"); err == nil {
+		t.Error("CheckCommand() with unknown format expected error")
+	}
+
+	own := fakeOutputCommand{outputs: []command.OutputMode{command.OutputText, command.OutputJSON}}
+	if err := CheckCommand(own, command.OutputJSON); err != nil {
+		t.Errorf("CheckCommand(own output, json) error = %v", err)
+	}
+	err = CheckCommand(own, "yaml")
+	if err == nil || !strings.Contains(err.Error(), "use one of: json, text") {
+		t.Errorf("CheckCommand(own output, yaml) error = %v", err)
+	}
+}
+
+func TestNewSearchFormatter(t *testing.T) {
+	if _, err := NewSearchFormatter(command.Runtime{Output: "yaml"}); err != nil {
+		t.Errorf("NewSearchFormatter(yaml) error = %v", err)
+	}
+	if _, err := NewLinksFormatter(command.Runtime{Output: command.OutputHTML}); err == nil {
+		t.Error("NewLinksFormatter(html) expected error")
+	}
+}
@@ -0,0 +1,131 @@
+package formatter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+// TemplateFormatter formats each search result or page with a
+// user-supplied text/template. Every output ends with a newline.
+type TemplateFormatter struct {
+	tmpl *template.Template
+	err  error
+}
+
+// NewTemplate returns a TemplateFormatter for the template text.
+func NewTemplate(text string) (*TemplateFormatter, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+func init() {
+	Register(Format{
+		Name:        "template",
+		Description: "each result rendered with --template",
+		New: func(rt command.Runtime) any {
+			if rt.Template == "" {
+				return &TemplateFormatter{err: errors.New("no template given (use --template or --template-file)")}
+			}
+			tmpl, err := ParseTemplate(rt.Template)
+			return &TemplateFormatter{tmpl: tmpl, err: err}
+		},
+	})
+}
+
+// ParseTemplate parses text as an output template, with the helper
+// functions listed in TemplateFuncs.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("output").Funcs(TemplateFuncs()).Parse(text)
+}
+
+// TemplateFuncs returns the helper functions available to output
+// templates. They are the ones the text formatter uses itself; their
+// last argument is the piped value, so that for example
+// {{.Snippet | truncate 80}} works.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"truncate":  func(n int, s string) string { return truncate(s, n) },
+		"normalize": normalize,
+		"commas":    commas,
+		"upper":     strings.ToUpper,
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"wrap": func(width int, s string) string {
+			return strings.Join(wrapANSI(normalize(s), width), "\n")
+		},
+	}
+}
+
+// commas formats an integer with thousands separators.
+func commas(v any) (string, error) {
+	switch n := v.(type) {
+	case int:
+		return formatViews(int64(n)), nil
+	case int64:
+		return formatViews(n), nil
+	case int32:
+		return formatViews(int64(n)), nil
+	case float64:
+		return formatViews(int64(n)), nil
+	}
+	return "", fmt.Errorf("commas: %T is not a number", v)
+}
+
+// FormatSearch renders each search result with the template.
+func (f *TemplateFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
+	var b strings.Builder
+	for _, r := range results {
+		if err := f.execute(&b, r); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// FormatPage renders a page with the template.
+func (f *TemplateFormatter) FormatPage(page *grokipedia.Page) (string, error) {
+	var b strings.Builder
+	err := f.execute(&b, page)
+	return b.String(), err
+}
+
+// FormatPages renders each page with the template.
+func (f *TemplateFormatter) FormatPages(pages []*grokipedia.Page) (string, error) {
+	var b strings.Builder
+	for _, page := range pages {
+		if err := f.execute(&b, page); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// NoResults returns no output.
+func (f *TemplateFormatter) NoResults() string {
+	return ""
+}
+
+func (f *TemplateFormatter) execute(b *strings.Builder, v any) error {
+	if f.err != nil {
+		return f.err
+	}
+	start := b.Len()
+	if err := f.tmpl.Execute(b, v); err != nil {
+		return err
+	}
+	if b.Len() > start && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package formatter
+
+import (
+	"testing"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+func TestTemplateFormatter_FormatSearch(t *testing.T) {
+	f, err := NewTemplate(`{{.Title | upper}} ({{.Slug}}) {{commas .ViewCount}}: {{.Snippet | truncate 12}}`)
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
+	}
+	got, err := f.FormatSearch([]grokipedia.SearchResult{
+		{Slug: "go", Title: "Go", Snippet: "A simple\nlanguage by Google.", ViewCount: 1234567},
+		{Slug: "c", Title: "C", ViewCount: 7},
+	})
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	want := "GO (go) 1,234,567: A simple lan...\nC (c) 7: \n"
+	if got != want {
+		t.Errorf("FormatSearch() = %q, want %q", got, want)
+	}
+	if got := f.NoResults(); got != "" {
+		t.Errorf("NoResults() = %q, want no output", got)
+	}
+}
+
+func TestTemplateFormatter_FormatPage(t *testing.T) {
+	f, err := NewTemplate("{{.Title}}\n{{range .Sections}}- {{.Title}}\n{{end}}{{json .Slug}} {{wrap 10 .Description}}")
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
+	}
+	page := &grokipedia.Page{Title: "Go", Slug: "go", Description: "A simple language.", Content: "## History\n\n## Design\n"}
+	got, err := f.FormatPages([]*grokipedia.Page{page, page})
+	if err != nil {
+		t.Fatalf("FormatPages() error = %v", err)
+	}
+	one := "Go\n- History\n- Design\n\"go\" A simple\nlanguage.\n"
+	if got != one+one {
+		t.Errorf("FormatPages() = %q, want %q", got, one+one)
+	}
+}
+
+func TestTemplateFormatter_Errors(t *testing.T) {
+	if _, err := NewTemplate("{{.Title"); err == nil {
+		t.Error("NewTemplate() with a syntax error expected error")
+	}
+	f, _ := NewTemplate("{{.Nope}}")
+	if _, err := f.FormatPage(&grokipedia.Page{}); err == nil {
+		t.Error("FormatPage() with an unknown field expected error")
+	}
+	tf, _ := Lookup(string(command.OutputTemplate))
+	if _, err := tf.New(command.Runtime{}).(*TemplateFormatter).FormatPage(&grokipedia.Page{}); err == nil {
+		t.Error("FormatPage() without a template expected error")
+	}
+}
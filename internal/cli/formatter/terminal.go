@@ -0,0 +1,476 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"grokir/internal/markdown"
+)
+
+// ANSI SGR sequences used by the terminal renderer. Each style has its
+// own reset so nested spans do not cancel enclosing ones.
+const (
+	sgrReset     = "\x1b[0m"
+	sgrBold      = "\x1b[1m"
+	sgrBoldOff   = "\x1b[22m"
+	sgrFaint     = "\x1b[2m"
+	sgrItalic    = "\x1b[3m"
+	sgrItalicOff = "\x1b[23m"
+	sgrUnder     = "\x1b[4m"
+	sgrUnderOff  = "\x1b[24m"
+	sgrCyan      = "\x1b[36m"
+	sgrYellow    = "\x1b[33m"
+	sgrBlue      = "\x1b[34m"
+	sgrMagenta   = "\x1b[35m"
+	sgrFgOff     = "\x1b[39m"
+)
+
+// ruleWidth is the width of horizontal rules when wrapping is off.
+const ruleWidth = 40
+
+// termRenderer renders parsed markdown for a terminal. Without color it
+// produces plain text that keeps the document structure readable.
+type termRenderer struct {
+	color bool
+	width int
+	b     strings.Builder
+}
+
+// renderMarkdown renders markdown source for a terminal, styling it with
+// ANSI escapes when color is set and wrapping it to width columns when
+// width is positive.
+func renderMarkdown(src string, color bool, width int) string {
+	r := &termRenderer{color: color, width: width}
+	r.blocks(markdown.Parse(src), "", "")
+	return r.b.String()
+}
+
+// plainMarkdown strips the inline markup and code fences from markdown
+// source, keeping its lines, line breaks and block markers as written,
+// for output that is neither styled nor wrapped.
+func plainMarkdown(src string) string {
+	r := &termRenderer{}
+	var fence string
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+				continue
+			}
+			r.b.WriteString(line)
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+			continue
+		case isPlainRule(trimmed):
+			r.b.WriteString(line)
+		default:
+			prefix, text := blockPrefix(line)
+			r.b.WriteString(prefix + r.inlines(markdown.ParseInline(strings.TrimRight(text, " "))))
+		}
+		r.b.WriteString("\n")
+	}
+	return strings.TrimSuffix(r.b.String(), "\n")
+}
+
+// isPlainRule reports whether trimmed is a thematic break such as
+// "---" or "* * *".
+func isPlainRule(trimmed string) bool {
+	if len(trimmed) < 3 || !strings.ContainsRune("-*_", rune(trimmed[0])) {
+		return false
+	}
+	return strings.Trim(trimmed, string(trimmed[0])+" ") == "" && strings.Count(trimmed, trimmed[:1]) >= 3
+}
+
+// blockPrefix splits line into its indentation and block markers
+// (quote, list item and heading markers) and the inline text after them.
+func blockPrefix(line string) (prefix, text string) {
+	i := 0
+	for {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		rest := line[i:]
+		switch {
+		case strings.HasPrefix(rest, ">"):
+			i++
+			continue
+		case len(rest) >= 2 && strings.ContainsRune("-*+", rune(rest[0])) && rest[1] == ' ':
+			i += 2
+			continue
+		}
+		if n := len(rest) - len(strings.TrimLeft(rest, "0123456789")); n > 0 && n < 10 &&
+			len(rest) > n+1 && (rest[n] == '.' || rest[n] == ')') && rest[n+1] == ' ' {
+			i += n + 2
+			continue
+		}
+		if n := len(rest) - len(strings.TrimLeft(rest, "#")); n > 0 && n <= 6 && len(rest) > n && rest[n] == ' ' {
+			i += n + 1
+		}
+		return line[:i], line[i:]
+	}
+}
+
+func (r *termRenderer) style(s, on, off string) string {
+	if !r.color || s == "" {
+		return s
+	}
+	return on + s + off
+}
+
+// blocks renders bs separated by blank lines, starting the first line
+// with first and every other line with rest.
+func (r *termRenderer) blocks(bs []markdown.Block, first, rest string) {
+	r.blocksSep(bs, first, rest, true)
+}
+
+func (r *termRenderer) blocksSep(bs []markdown.Block, first, rest string, blank bool) {
+	for i, b := range bs {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		} else if blank {
+			r.b.WriteString(strings.TrimRight(stripANSI(rest), " "))
+			r.b.WriteString("\n")
+		}
+		r.block(b, prefix, rest)
+	}
+}
+
+func (r *termRenderer) block(b markdown.Block, first, rest string) {
+	switch b.Kind {
+	case markdown.Heading:
+		text := markdown.PlainText(markdown.ParseInline(b.Text))
+		if r.color {
+			switch b.Level {
+			case 1:
+				text = sgrBold + sgrUnder + sgrMagenta + text + sgrReset
+			case 2:
+				text = sgrBold + sgrCyan + text + sgrReset
+			default:
+				text = sgrBold + text + sgrReset
+			}
+		} else {
+			text = strings.Repeat("#", b.Level) + " " + text
+		}
+		r.lines(wrapANSI(text, r.avail(rest)), first, rest)
+	case markdown.Paragraph:
+		r.lines(wrapANSI(r.inlines(markdown.ParseInline(b.Text)), r.avail(rest)), first, rest)
+	case markdown.CodeBlock:
+		var lines []string
+		for _, l := range strings.Split(b.Text, "\n") {
+			lines = append(lines, "    "+r.style(l, sgrYellow, sgrFgOff))
+		}
+		r.lines(lines, first, rest)
+	case markdown.Quote:
+		bar := "> "
+		if r.color {
+			bar = sgrFaint + "│" + sgrReset + " "
+		}
+		r.blocks(b.Children, first+bar, rest+bar)
+	case markdown.List:
+		r.list(b, first, rest)
+	case markdown.Table:
+		r.table(b, first, rest)
+	case markdown.Rule:
+		w := ruleWidth
+		if r.width > 0 {
+			w = max(1, r.avail(rest))
+		}
+		if r.color {
+			r.lines([]string{sgrFaint + strings.Repeat("─", w) + sgrReset}, first, rest)
+		} else {
+			r.lines([]string{strings.Repeat("-", w)}, first, rest)
+		}
+	}
+}
+
+// avail returns the columns left for content after prefix, or zero when
+// wrapping is disabled.
+func (r *termRenderer) avail(prefix string) int {
+	if r.width <= 0 {
+		return 0
+	}
+	return max(r.width-visibleLen(prefix), 20)
+}
+
+func (r *termRenderer) lines(lines []string, first, rest string) {
+	for i, l := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		r.b.WriteString(strings.TrimRight(prefix+l, " "))
+		r.b.WriteString("\n")
+	}
+}
+
+func (r *termRenderer) list(b markdown.Block, first, rest string) {
+	for i, item := range b.Items {
+		m := "- "
+		if r.color {
+			m = "• "
+		}
+		if b.Ordered {
+			m = fmt.Sprintf("%d. ", b.Start+i)
+		}
+		pad := strings.Repeat(" ", utf8.RuneCountInString(m))
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if b.Loose && i > 0 {
+			r.b.WriteString(strings.TrimRight(stripANSI(rest), " "))
+			r.b.WriteString("\n")
+		}
+		if len(item) == 0 {
+			r.lines([]string{""}, prefix+r.style(m, sgrCyan, sgrFgOff), rest)
+			continue
+		}
+		r.blocksSep(item, prefix+r.style(m, sgrCyan, sgrFgOff), rest+pad, b.Loose)
+	}
+}
+
+func (r *termRenderer) table(b markdown.Block, first, rest string) {
+	cells := func(row []string) []string {
+		out := make([]string, len(row))
+		for i, c := range row {
+			out[i] = r.inlines(markdown.ParseInline(c))
+		}
+		return out
+	}
+	header := cells(b.Header)
+	rows := make([][]string, len(b.Rows))
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = visibleLen(h)
+	}
+	for i, row := range b.Rows {
+		rows[i] = cells(row)
+		for j, c := range rows[i] {
+			widths[j] = max(widths[j], visibleLen(c))
+		}
+	}
+
+	sep := " | "
+	if r.color {
+		sep = sgrFaint + " │ " + sgrReset
+	}
+	format := func(row []string, bold bool) string {
+		parts := make([]string, len(row))
+		for i, c := range row {
+			var align markdown.Align
+			if i < len(b.Align) {
+				align = b.Align[i]
+			}
+			c = pad(c, widths[i], align)
+			if bold {
+				c = r.style(c, sgrBold, sgrBoldOff)
+			}
+			parts[i] = c
+		}
+		return strings.Join(parts, sep)
+	}
+
+	lines := []string{format(header, true)}
+	dashes := make([]string, len(widths))
+	for i, w := range widths {
+		dashes[i] = strings.Repeat("-", w)
+	}
+	if r.color {
+		for i, w := range widths {
+			dashes[i] = strings.Repeat("─", w)
+		}
+		lines = append(lines, sgrFaint+strings.Join(dashes, "─┼─")+sgrReset)
+	} else {
+		lines = append(lines, strings.Join(dashes, "-|-"))
+	}
+	for _, row := range rows {
+		lines = append(lines, format(row, false))
+	}
+	r.lines(lines, first, rest)
+}
+
+func pad(s string, width int, align markdown.Align) string {
+	gap := width - visibleLen(s)
+	if gap <= 0 {
+		return s
+	}
+	switch align {
+	case markdown.AlignRight:
+		return strings.Repeat(" ", gap) + s
+	case markdown.AlignCenter:
+		left := gap / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", gap-left)
+	}
+	return s + strings.Repeat(" ", gap)
+}
+
+// inlines renders inline spans; without color, markup is dropped except
+// for code spans and link targets.
+func (r *termRenderer) inlines(ins []markdown.Inline) string {
+	var b strings.Builder
+	for _, in := range ins {
+		switch in.Kind {
+		case markdown.Text:
+			b.WriteString(in.Text)
+		case markdown.Emphasis:
+			b.WriteString(r.style(r.inlines(in.Children), sgrItalic, sgrItalicOff))
+		case markdown.Strong:
+			b.WriteString(r.style(r.inlines(in.Children), sgrBold, sgrBoldOff))
+		case markdown.Code:
+			if r.color {
+				b.WriteString(sgrYellow + in.Text + sgrFgOff)
+			} else {
+				b.WriteString("`" + in.Text + "`")
+			}
+		case markdown.Link:
+			text := r.inlines(in.Children)
+			if r.color {
+				b.WriteString(sgrUnder + sgrBlue + text + sgrFgOff + sgrUnderOff)
+			} else {
+				b.WriteString(text)
+			}
+			if stripANSI(text) != in.URL {
+				b.WriteString(r.style(" ("+in.URL+")", sgrFaint, sgrBoldOff))
+			}
+		case markdown.Image:
+			b.WriteString(r.style("[image: "+in.Text+"]", sgrFaint, sgrBoldOff))
+		case markdown.LineBreak:
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// sgrState tracks which ANSI styles are active so they can be closed at
+// the end of a wrapped line and reopened after the next line's prefix.
+type sgrState struct {
+	bold, faint, italic, under bool
+	fg                         string
+}
+
+func (st *sgrState) apply(code string) {
+	switch code {
+	case sgrReset:
+		*st = sgrState{}
+	case sgrBold:
+		st.bold = true
+	case sgrFaint:
+		st.faint = true
+	case sgrBoldOff:
+		st.bold, st.faint = false, false
+	case sgrItalic:
+		st.italic = true
+	case sgrItalicOff:
+		st.italic = false
+	case sgrUnder:
+		st.under = true
+	case sgrUnderOff:
+		st.under = false
+	case sgrFgOff:
+		st.fg = ""
+	case sgrCyan, sgrYellow, sgrBlue, sgrMagenta:
+		st.fg = code
+	}
+}
+
+func (st sgrState) String() string {
+	var b strings.Builder
+	if st.bold {
+		b.WriteString(sgrBold)
+	}
+	if st.faint {
+		b.WriteString(sgrFaint)
+	}
+	if st.italic {
+		b.WriteString(sgrItalic)
+	}
+	if st.under {
+		b.WriteString(sgrUnder)
+	}
+	b.WriteString(st.fg)
+	return b.String()
+}
+
+// wrapANSI word-wraps s to width visible columns, keeping ANSI styles
+// intact across line breaks. Newlines in s are hard breaks. A width of
+// zero disables wrapping.
+func wrapANSI(s string, width int) []string {
+	var out []string
+	var st sgrState
+	for _, hard := range strings.Split(s, "\n") {
+		var line strings.Builder
+		line.WriteString(st.String())
+		lineLen := 0
+		for _, word := range strings.Fields(hard) {
+			wl := visibleLen(word)
+			if lineLen > 0 && width > 0 && lineLen+1+wl > width {
+				if st != (sgrState{}) {
+					line.WriteString(sgrReset)
+				}
+				out = append(out, line.String())
+				line.Reset()
+				line.WriteString(st.String())
+				lineLen = 0
+			}
+			if lineLen > 0 {
+				line.WriteByte(' ')
+				lineLen++
+			}
+			line.WriteString(word)
+			lineLen += wl
+			for _, code := range sgrCodes(word) {
+				st.apply(code)
+			}
+		}
+		out = append(out, line.String())
+	}
+	return out
+}
+
+// sgrCodes returns the escape sequences in s, in order.
+func sgrCodes(s string) []string {
+	var codes []string
+	for {
+		i := strings.Index(s, "\x1b[")
+		if i < 0 {
+			return codes
+		}
+		j := strings.IndexByte(s[i:], 'm')
+		if j < 0 {
+			return codes
+		}
+		codes = append(codes, s[i:i+j+1])
+		s = s[i+j+1:]
+	}
+}
+
+// stripANSI removes ANSI SGR escape sequences from s.
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b[") {
+		return s
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "\x1b[")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		j := strings.IndexByte(s[i:], 'm')
+		if j < 0 {
+			return b.String()
+		}
+		s = s[i+j+1:]
+	}
+}
+
+// visibleLen returns the number of runes in s that are not part of an
+// ANSI escape sequence.
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(stripANSI(s))
+}
@@ -0,0 +1,83 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown_Plain(t *testing.T) {
+	src := "# Kubernetes\n\n**Kubernetes** is an *orchestrator*. See [Docker](/page/Docker) and `kubectl`.\n\n" +
+		"- one\n- two\n  1. nested\n\n> quoted\n\n```\ncode here\n```\n\n| A | B |\n|---|--:|\n| x | 10 |\n| yy | 2 |"
+
+	got := renderMarkdown(src, false, 0)
+
+	want := "# Kubernetes\n\n" +
+		"Kubernetes is an orchestrator. See Docker (/page/Docker) and `kubectl`.\n\n" +
+		"- one\n- two\n  1. nested\n\n" +
+		"> quoted\n\n" +
+		"    code here\n\n" +
+		"A  |  B\n---|---\nx  | 10\nyy |  2\n"
+	if got != want {
+		t.Errorf("renderMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Error("plain rendering contains ANSI escapes")
+	}
+}
+
+func TestPlainMarkdown(t *testing.T) {
+	src := "# Kubernetes\n\n**Kubernetes** is an  *orchestrator*.\nSee [Docker](/page/Docker) and `kubectl`.  \n\n" +
+		"* one\n* two\n  1. nested\n\n> quoted\n\n---\n\n```go\n  code   here\n```\n\n| A | **B** |\n|---|--:|\n| x | 10 |\n"
+
+	got := plainMarkdown(src)
+
+	want := "# Kubernetes\n\nKubernetes is an  orchestrator.\nSee Docker (/page/Docker) and `kubectl`.\n\n" +
+		"* one\n* two\n  1. nested\n\n> quoted\n\n---\n\n  code   here\n\n| A | B |\n|---|--:|\n| x | 10 |\n"
+	if got != want {
+		t.Errorf("plainMarkdown() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRenderMarkdown_Color(t *testing.T) {
+	got := renderMarkdown("## History\n\nSome **bold** and *italic* text.", true, 0)
+
+	for _, want := range []string{
+		sgrBold + sgrCyan + "History" + sgrReset,
+		sgrBold + "bold" + sgrBoldOff,
+		sgrItalic + "italic" + sgrItalicOff,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderMarkdown() missing %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "**") || strings.Contains(got, "##") {
+		t.Errorf("renderMarkdown() kept markdown syntax: %q", got)
+	}
+}
+
+func TestRenderMarkdown_Wrap(t *testing.T) {
+	src := "- " + strings.Repeat("word ", 20) + "\n\n> " + strings.Repeat("quote ", 20)
+
+	for _, color := range []bool{false, true} {
+		got := renderMarkdown(src, color, 30)
+		for _, line := range strings.Split(strings.TrimRight(got, "\n"), "\n") {
+			if n := visibleLen(line); n > 30 {
+				t.Errorf("color=%t: line %q has width %d, want at most 30", color, stripANSI(line), n)
+			}
+		}
+		lines := strings.Split(stripANSI(got), "\n")
+		if !strings.HasPrefix(lines[1], "  word") {
+			t.Errorf("color=%t: list continuation not indented: %q", color, lines[1])
+		}
+	}
+}
+
+func TestWrapANSI_ReopensStyles(t *testing.T) {
+	lines := wrapANSI(sgrBold+"one two three"+sgrBoldOff, 5)
+	if len(lines) != 3 {
+		t.Fatalf("wrapANSI() = %q, want 3 lines", lines)
+	}
+	if !strings.HasSuffix(lines[0], sgrReset) || !strings.HasPrefix(lines[1], sgrBold) {
+		t.Errorf("wrapANSI() did not close and reopen bold: %q", lines)
+	}
+}
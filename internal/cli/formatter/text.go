@@ -4,16 +4,32 @@ import (
 	"fmt"
 	"strings"
 
+	"grokir/internal/cli/command"
 	"grokir/internal/grokipedia"
 )
 
 // TextFormatter formats output as human-readable plain text.
-type TextFormatter struct{}
+type TextFormatter struct {
+	// Color enables ANSI styling of page content and search
+	// highlights.
+	Color bool
+	// Width wraps page content to this many columns; zero disables
+	// wrapping.
+	Width int
+}
 
 func NewText() *TextFormatter {
 	return &TextFormatter{}
 }
 
+func init() {
+	Register(Format{
+		Name:        "text",
+		Description: "human-readable text, styled on terminals",
+		New:         func(rt command.Runtime) any { return newTextFor(rt) },
+	})
+}
+
 func normalize(s string) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	s = strings.ReplaceAll(s, "\t", " ")
@@ -50,32 +66,141 @@ func (f *TextFormatter) FormatSearch(results []grokipedia.SearchResult) (string,
 		if i > 0 {
 			b.WriteString("\n")
 		}
-		b.WriteString(fmt.Sprintf("%d) %s\n", i+1, r.Title))
+		b.WriteString(fmt.Sprintf("%d) %s\n", i+1, f.highlight(r.Title, r.TitleHighlights)))
 		b.WriteString(fmt.Sprintf("   slug: %s | relevance: %.2f | views: %s\n",
 			r.Slug, r.RelevanceScore, formatViews(r.ViewCount)))
 		if r.Snippet != "" {
-			b.WriteString(fmt.Sprintf("   %s\n", truncate(r.Snippet, 200)))
+			snippet := excerpt(r.Snippet, 200, r.SnippetHighlights)
+			b.WriteString(fmt.Sprintf("   %s\n", f.highlight(snippet, r.SnippetHighlights)))
 		}
 	}
 	return b.String(), nil
 }
 
-// FormatPage renders a page as a readable text document.
+// highlight emphasises the matched terms in s: bold and colored on a
+// terminal, wrapped in markdown strong markers otherwise.
+func (f *TextFormatter) highlight(s string, terms []string) string {
+	if f.Color {
+		return markHighlights(s, terms, sgrBold+sgrYellow, sgrFgOff+sgrBoldOff)
+	}
+	return markHighlights(s, terms, markOpen, markClose)
+}
+
+// FormatPage renders a page as a readable text document, with the
+// markdown content styled and wrapped for the terminal when Color is
+// set, and otherwise left as written apart from its markup.
 func (f *TextFormatter) FormatPage(page *grokipedia.Page) (string, error) {
 	var b strings.Builder
-	b.WriteString(fmt.Sprintf("Title: %s\n", page.Title))
+	title := page.Title
+	if f.Color {
+		title = sgrBold + title + sgrReset
+	}
+	b.WriteString(fmt.Sprintf("Title: %s\n", title))
 	b.WriteString(fmt.Sprintf("Slug: %s\n", page.Slug))
 	if page.Description != "" {
-		b.WriteString(fmt.Sprintf("\n%s\n", normalize(page.Description)))
+		b.WriteString("\n")
+		width := f.Width
+		if !f.Color {
+			width = 0
+		}
+		for _, line := range wrapANSI(normalize(page.Description), width) {
+			b.WriteString(line + "\n")
+		}
 	}
 	b.WriteString(strings.Repeat("-", 40))
 	b.WriteString("\n")
-	if page.Content != "" {
-		b.WriteString(page.Content)
+	if page.Content == "" {
+		return b.String(), nil
 	}
+	// Plain output keeps the content's own line breaks, so that it can
+	// be piped to other tools, and only drops the markup.
+	if !f.Color {
+		b.WriteString(plainMarkdown(page.Content))
+		return b.String(), nil
+	}
+	b.WriteString(renderMarkdown(page.Content, f.Color, f.Width))
 	return b.String(), nil
 }
 
+// FormatTOC renders a page's table of contents as an indented outline,
+// with each heading followed by its anchor.
+func (f *TextFormatter) FormatTOC(page *grokipedia.Page, sections []grokipedia.Section) (string, error) {
+	var b strings.Builder
+	title := page.Title
+	if f.Color {
+		title = sgrBold + title + sgrReset
+	}
+	b.WriteString(fmt.Sprintf("Title: %s\n", title))
+	b.WriteString(fmt.Sprintf("Slug: %s\n", page.Slug))
+	b.WriteString(strings.Repeat("-", 40))
+	b.WriteString("\n")
+	if len(sections) == 0 {
+		b.WriteString("No sections.\n")
+	}
+	f.writeTOC(&b, sections, 0)
+	return b.String(), nil
+}
+
+func (f *TextFormatter) writeTOC(b *strings.Builder, sections []grokipedia.Section, depth int) {
+	for _, s := range sections {
+		anchor := "#" + s.Anchor
+		if f.Color {
+			anchor = sgrFaint + anchor + sgrReset
+		}
+		b.WriteString(fmt.Sprintf("%s%s  %s\n", strings.Repeat("  ", depth), s.Title, anchor))
+		f.writeTOC(b, s.Children, depth+1)
+	}
+}
+
+// FormatLinks renders the links of a page as three lists: internal
+// links with their target slugs, external links and citations.
+func (f *TextFormatter) FormatLinks(links grokipedia.PageLinks) (string, error) {
+	var b strings.Builder
+	f.linksHeading(&b, "Internal links", len(links.Internal))
+	for _, l := range links.Internal {
+		line := fmt.Sprintf("  %s -> %s", linkText(l), l.Slug)
+		if l.Broken {
+			line += " (broken)"
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+	f.linksHeading(&b, "External links", len(links.External))
+	for _, l := range links.External {
+		b.WriteString(fmt.Sprintf("  %s <%s>\n", linkText(l), l.URL))
+	}
+	b.WriteString("\n")
+	f.linksHeading(&b, "Citations", len(links.Citations))
+	for _, c := range links.Citations {
+		var label string
+		if c.ID != "" {
+			label = "[" + c.ID + "] "
+		}
+		if c.Title != "" {
+			label += normalize(c.Title) + " "
+		}
+		b.WriteString(fmt.Sprintf("  %s<%s>\n", label, c.URL))
+	}
+	return b.String(), nil
+}
+
+func (f *TextFormatter) linksHeading(b *strings.Builder, title string, n int) {
+	heading := fmt.Sprintf("%s (%d)", title, n)
+	if f.Color {
+		heading = sgrBold + heading + sgrReset
+	}
+	b.WriteString(heading + "\n")
+}
+
+// linkText returns the text of a link, or its URL when the text is
+// empty.
+func linkText(l grokipedia.Link) string {
+	if t := normalize(l.Text); t != "" {
+		return t
+	}
+	return l.URL
+}
+
 // NoResults returns a human-readable message when search returns no results.
 func (f *TextFormatter) NoResults() string {
 	return "No results found. Try different keywords.\n"
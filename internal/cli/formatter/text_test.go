@@ -90,6 +90,41 @@ func TestTextFormatter_FormatSearch(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_FormatSearchHighlights(t *testing.T) {
+	results := []grokipedia.SearchResult{{
+		Title:             "Go (programming language)",
+		Slug:              "Go_(programming_language)",
+		Snippet:           strings.Repeat("filler words here ", 20) + "Go was designed at Google in 2007.",
+		TitleHighlights:   []string{"go"},
+		SnippetHighlights: []string{"google"},
+	}}
+
+	t.Run("plain", func(t *testing.T) {
+		got, err := NewText().FormatSearch(results)
+		if err != nil {
+			t.Fatalf("FormatSearch() error = %v", err)
+		}
+		for _, sub := range []string{"1) **Go** (programming language)", "at **Google** in 2007."} {
+			if !strings.Contains(got, sub) {
+				t.Errorf("FormatSearch() missing %q in:\n%s", sub, got)
+			}
+		}
+	})
+
+	t.Run("color", func(t *testing.T) {
+		got, err := (&TextFormatter{Color: true}).FormatSearch(results)
+		if err != nil {
+			t.Fatalf("FormatSearch() error = %v", err)
+		}
+		if !strings.Contains(got, sgrBold+sgrYellow+"Google"+sgrFgOff+sgrBoldOff) {
+			t.Errorf("FormatSearch() missing styled highlight in:\n%q", got)
+		}
+		if strings.Contains(got, "**") {
+			t.Errorf("FormatSearch() should not contain markers in:\n%q", got)
+		}
+	})
+}
+
 func TestTextFormatter_FormatPage(t *testing.T) {
 	f := NewText()
 
@@ -147,6 +182,67 @@ func TestTextFormatter_FormatPage(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_FormatPagePlain(t *testing.T) {
+	f := &TextFormatter{Width: 20}
+	content := "A first line that is longer than twenty columns,\nthen a  *second*  line.\n"
+	got, err := f.FormatPage(&grokipedia.Page{Title: "T", Slug: "t", Content: content})
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	want := "A first line that is longer than twenty columns,\nthen a  second  line.\n"
+	if !strings.HasSuffix(got, "---\n"+want) {
+		t.Errorf("FormatPage() =\n%s\nwant content\n%s", got, want)
+	}
+}
+
+func TestTextFormatter_FormatTOC(t *testing.T) {
+	page := &grokipedia.Page{
+		Title:   "Go",
+		Slug:    "go",
+		Content: "Intro.\n\n## History\n\n### Early years\n\n## Design\n",
+	}
+	got, err := NewText().FormatTOC(page, page.Sections())
+	if err != nil {
+		t.Fatalf("FormatTOC() error = %v", err)
+	}
+	want := "Title: Go\nSlug: go\n" + strings.Repeat("-", 40) + "\n" +
+		"History  #history\n" +
+		"  Early years  #early-years\n" +
+		"Design  #design\n"
+	if got != want {
+		t.Errorf("FormatTOC() = %q, want %q", got, want)
+	}
+}
+
+func TestTextFormatter_FormatLinks(t *testing.T) {
+	links := grokipedia.PageLinks{
+		Slug: "go",
+		Internal: []grokipedia.Link{
+			{Text: "Rob Pike", URL: "/page/Rob_Pike", Slug: "Rob_Pike"},
+			{URL: "/page/Gone", Slug: "Gone", Broken: true},
+		},
+		External:  []grokipedia.Link{{Text: "Google", URL: "https://google.com"}},
+		Citations: []grokipedia.Citation{{ID: "1", Title: "Go site", URL: "https://go.dev"}, {URL: "https://example.com"}},
+	}
+	got, err := NewText().FormatLinks(links)
+	if err != nil {
+		t.Fatalf("FormatLinks() error = %v", err)
+	}
+	want := "Internal links (2)\n" +
+		"  Rob Pike -> Rob_Pike\n" +
+		"  /page/Gone -> Gone (broken)\n" +
+		"\n" +
+		"External links (1)\n" +
+		"  Google <https://google.com>\n" +
+		"\n" +
+		"Citations (2)\n" +
+		"  [1] Go site <https://go.dev>\n" +
+		"  <https://example.com>\n"
+	if got != want {
+		t.Errorf("FormatLinks() = %q, want %q", got, want)
+	}
+}
+
 func TestTextFormatter_NoResults(t *testing.T) {
 	f := NewText()
 	got := f.NoResults()
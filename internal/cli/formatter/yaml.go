@@ -0,0 +1,234 @@
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"grokir/internal/cli/command"
+	"grokir/internal/grokipedia"
+)
+
+// YAMLFormatter formats output as YAML. It has the same fields, in the
+// same order, as JSON output.
+type YAMLFormatter struct {
+	json JSONFormatter
+}
+
+func NewYAML() *YAMLFormatter {
+	return &YAMLFormatter{}
+}
+
+func init() {
+	Register(Format{
+		Name:        "yaml",
+		Description: "YAML with the same fields as JSON",
+		New: func(rt command.Runtime) any {
+			return &YAMLFormatter{json: JSONFormatter{Fields: rt.Fields}}
+		},
+		Fields: JSONFields,
+	})
+}
+
+// FormatSearch renders search results as a YAML sequence.
+func (f *YAMLFormatter) FormatSearch(results []grokipedia.SearchResult) (string, error) {
+	return toYAML(f.json.FormatSearch(results))
+}
+
+// FormatPage renders a page as a YAML mapping, including its section
+// tree.
+func (f *YAMLFormatter) FormatPage(page *grokipedia.Page) (string, error) {
+	return toYAML(f.json.FormatPage(page))
+}
+
+// FormatPages renders several pages as a YAML sequence.
+func (f *YAMLFormatter) FormatPages(pages []*grokipedia.Page) (string, error) {
+	return toYAML(f.json.FormatPages(pages))
+}
+
+// FormatTOC renders a page's table of contents as a YAML mapping.
+func (f *YAMLFormatter) FormatTOC(page *grokipedia.Page, sections []grokipedia.Section) (string, error) {
+	return toYAML(f.json.FormatTOC(page, sections))
+}
+
+// FormatLinks renders the links of a page as a YAML mapping.
+func (f *YAMLFormatter) FormatLinks(links grokipedia.PageLinks) (string, error) {
+	return toYAML(f.json.FormatLinks(links))
+}
+
+// NoResults returns an empty YAML sequence for when search returns no
+// results.
+func (f *YAMLFormatter) NoResults() string {
+	return "[]\n"
+}
+
+// toYAML converts the JSON document data to YAML.
+func toYAML(data string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	n, err := parseJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("YAML error: %w", err)
+	}
+
+	var b strings.Builder
+	switch {
+	case n.empty():
+		b.WriteString(emptyYAML(n) + "\n")
+	case n.isObject:
+		writeYAMLMapping(&b, n, 0, false)
+	case n.isArray:
+		writeYAMLSequence(&b, n, 0, false)
+	case strings.HasPrefix(yamlValue(n), "|"):
+		// A bare multi-line string has no key to indent it below.
+		b.WriteString(yamlString(n.value.(string)) + "\n")
+	default:
+		b.WriteString(yamlValue(n) + "\n")
+	}
+	return b.String(), nil
+}
+
+// yamlValue returns the YAML form of a scalar.
+func yamlValue(n *jsonNode) string {
+	switch v := n.value.(type) {
+	case string:
+		return yamlScalar(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return "null"
+}
+
+func emptyYAML(n *jsonNode) string {
+	if n.isObject {
+		return "{}"
+	}
+	return "[]"
+}
+
+// writeYAMLMapping writes the entries of an object at the given
+// indentation. When inline is set, the first entry continues the
+// current line, as after a sequence dash.
+func writeYAMLMapping(b *strings.Builder, n *jsonNode, indent int, inline bool) {
+	for i, key := range n.keys {
+		if i > 0 || !inline {
+			b.WriteString(strings.Repeat(" ", indent))
+		}
+		if yamlPlain(key) {
+			b.WriteString(key + ":")
+		} else {
+			b.WriteString(yamlString(key) + ":")
+		}
+		writeYAMLValue(b, n.children[i], indent+2, false)
+	}
+}
+
+// writeYAMLSequence writes the items of an array at the given
+// indentation, with inline as for writeYAMLMapping.
+func writeYAMLSequence(b *strings.Builder, n *jsonNode, indent int, inline bool) {
+	for i, child := range n.children {
+		if i > 0 || !inline {
+			b.WriteString(strings.Repeat(" ", indent))
+		}
+		b.WriteString("-")
+		writeYAMLValue(b, child, indent+2, true)
+	}
+}
+
+// writeYAMLValue writes n after a mapping key or sequence dash. Nested
+// collections are indented by indent; an object in a sequence starts on
+// the dash's line.
+func writeYAMLValue(b *strings.Builder, n *jsonNode, indent int, item bool) {
+	switch {
+	case n.empty():
+		b.WriteString(" " + emptyYAML(n) + "\n")
+	case n.isObject && item:
+		b.WriteString(" ")
+		writeYAMLMapping(b, n, indent, true)
+	case n.isObject:
+		b.WriteString("\n")
+		writeYAMLMapping(b, n, indent, false)
+	case n.isArray:
+		b.WriteString("\n")
+		writeYAMLSequence(b, n, indent, false)
+	default:
+		v := yamlValue(n)
+		header, body, block := strings.Cut(v, "\n")
+		if !block {
+			b.WriteString(" " + v + "\n")
+			return
+		}
+		// Block scalars are indented below their key.
+		b.WriteString(" " + header + "\n")
+		for line := range strings.Lines(body) {
+			if line != "\n" {
+				b.WriteString(strings.Repeat(" ", indent))
+			}
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// yamlScalar returns s as a YAML scalar: plain when that cannot be read
+// as anything but the same string, a literal block for multi-line text,
+// and double-quoted otherwise. Block scalars are returned as the header
+// and the unindented lines, for writeYAMLValue to indent.
+func yamlScalar(s string) string {
+	if yamlPlain(s) {
+		return s
+	}
+	if strings.Contains(s, "\n") && yamlBlock(s) {
+		trimmed := strings.TrimSuffix(s, "\n")
+		header := "|-"
+		if trimmed != s {
+			header = "|"
+		}
+		return header + "\n" + trimmed
+	}
+	return yamlString(s)
+}
+
+// yamlPlain reports whether s can be written as a plain YAML scalar.
+func yamlPlain(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`0123456789.+", rune(s[0])) {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r == '\ufeff' {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlBlock reports whether the multi-line string s can be written as a
+// literal block scalar that reads back unchanged.
+func yamlBlock(s string) bool {
+	if strings.HasPrefix(s, " ") || strings.HasPrefix(s, "\n") || strings.HasSuffix(s, "\n\n") {
+		return false
+	}
+	for _, r := range s {
+		if (r < ' ' && r != '\n' && r != '\t') || r == 0x7f || r == '\ufeff' || r == '\r' {
+			return false
+		}
+	}
+	return true
+}
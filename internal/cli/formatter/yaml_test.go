@@ -0,0 +1,93 @@
+package formatter
+
+import (
+	"testing"
+
+	"grokir/internal/grokipedia"
+)
+
+func TestYAMLFormatter_FormatSearch(t *testing.T) {
+	results := []grokipedia.SearchResult{
+		{Slug: "Go", Title: "Go: a language", Snippet: "yes", RelevanceScore: 0.5, ViewCount: 12, TitleHighlights: []string{"go"}},
+	}
+	got, err := NewYAML().FormatSearch(results)
+	if err != nil {
+		t.Fatalf("FormatSearch() error = %v", err)
+	}
+	want := `- slug: Go
+  title: "Go: a language"
+  snippet: "yes"
+  relevance_score: 0.5
+  view_count: "12"
+  title_highlights:
+    - go
+  snippet_highlights: null
+`
+	if got != want {
+		t.Errorf("FormatSearch() =\n%s\nwant\n%s", got, want)
+	}
+	if got := NewYAML().NoResults(); got != "[]\n" {
+		t.Errorf("NoResults() = %q", got)
+	}
+}
+
+func TestYAMLFormatter_FormatPage(t *testing.T) {
+	page := &grokipedia.Page{
+		Title:   "Go",
+		Slug:    "Go",
+		Content: "# Go\n\n  Indented.\n\n## History\n",
+	}
+	got, err := NewYAML().FormatPage(page)
+	if err != nil {
+		t.Fatalf("FormatPage() error = %v", err)
+	}
+	want := `title: Go
+slug: Go
+description: ""
+content: |
+  # Go
+
+    Indented.
+
+  ## History
+sections:
+  - title: Go
+    level: 1
+    anchor: go
+    children:
+      - title: History
+        level: 2
+        anchor: history
+`
+	if got != want {
+		t.Errorf("FormatPage() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"12", `"12"`},
+		{"1.5e3", `"1.5e3"`},
+		{"2025-01-02", `"2025-01-02"`},
+		{"- item", `"- item"`},
+		{"a: b", `"a: b"`},
+		{"a #b", `"a #b"`},
+		{" padded", `" padded"`},
+		{"tab\there", `"tab\there"`},
+		{"one\ntwo", "|-\none\ntwo"},
+		{"one\ntwo\n", "|\none\ntwo"},
+		{" one\ntwo", `" one\ntwo"`},
+		{"one\n\n", `"one\n\n"`},
+	}
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
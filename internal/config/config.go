@@ -0,0 +1,182 @@
+// Package config loads grokir settings from a TOML config file and
+// GROKIR_* environment variables.
+//
+// Settings resolve in order of precedence: command-line flag, then
+// environment variable, then config file, then built-in default. Flags
+// are handled by the caller; this package covers the rest.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// PathEnv overrides the config file location.
+const PathEnv = "GROKIR_CONFIG"
+
+// Source identifies where a resolved value came from.
+type Source string
+
+const (
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceDefault Source = "default"
+)
+
+// Config holds the contents of a config file together with the process
+// environment.
+type Config struct {
+	path   string
+	values map[string]string
+	// lookupEnv looks up environment variables, like os.LookupEnv;
+	// tests replace it.
+	lookupEnv func(string) (string, bool)
+}
+
+// DefaultPath returns the config file location: $GROKIR_CONFIG if set,
+// otherwise $XDG_CONFIG_HOME/grokir/config.toml (or the platform
+// equivalent).
+func DefaultPath() (string, error) {
+	if p := os.Getenv(PathEnv); p != "" {
+		return p, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(base, "grokir", "config.toml"), nil
+}
+
+// Load reads the config file at path. A missing file yields an empty
+// configuration. Values are validated when they are read rather than
+// here, so that a bad value does not stop it being fixed with Set.
+func Load(path string) (*Config, error) {
+	c := &Config{path: path, values: make(map[string]string), lookupEnv: os.LookupEnv}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	values, err := parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	maps.Copy(c.values, values)
+	return c, nil
+}
+
+// Path returns the location of the config file.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Get resolves a setting from the environment, the config file or the
+// built-in default, in that order. A variable that is set overrides the
+// file even when it is empty. An invalid value is returned with its
+// source and an error.
+func (c *Config) Get(name string) (string, Source, error) {
+	k, ok := Lookup(name)
+	if !ok {
+		return "", "", fmt.Errorf("unknown config key: %s", name)
+	}
+	if v, ok := c.lookupEnv(k.Env); ok {
+		if err := k.Validate(v); err != nil {
+			return v, SourceEnv, fmt.Errorf("%s: %w", k.Env, err)
+		}
+		return v, SourceEnv, nil
+	}
+	if v, ok := c.values[name]; ok {
+		if err := k.Validate(v); err != nil {
+			return v, SourceFile, fmt.Errorf("%s: %w", c.path, err)
+		}
+		return v, SourceFile, nil
+	}
+	return k.Default, SourceDefault, nil
+}
+
+// Set stores a validated value for a setting. Call Save to persist it.
+func (c *Config) Set(name, value string) error {
+	k, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown config key: %s", name)
+	}
+	if err := k.Validate(value); err != nil {
+		return err
+	}
+	c.values[name] = value
+	return nil
+}
+
+// Save writes the file-backed settings to Path, creating its directory
+// if needed.
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	names := make([]string, 0, len(c.values))
+	for name := range c.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := os.WriteFile(c.path, []byte(encode(names, c.values)), 0o644); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
+// String resolves a setting. An invalid value is replaced by the
+// setting's default and reported in the error.
+func (c *Config) String(name string) (string, error) {
+	v, _, err := c.Get(name)
+	if err != nil {
+		if k, ok := Lookup(name); ok {
+			return k.Default, err
+		}
+	}
+	return v, err
+}
+
+// The accessors below resolve settings of their type like String.
+// Valid values and defaults always parse, so only String's error is
+// returned.
+
+// Int resolves an integer setting.
+func (c *Config) Int(name string) (int, error) {
+	v, err := c.String(name)
+	n, _ := strconv.Atoi(v)
+	return n, err
+}
+
+// Float resolves a floating-point setting.
+func (c *Config) Float(name string) (float64, error) {
+	v, err := c.String(name)
+	f, _ := strconv.ParseFloat(v, 64)
+	return f, err
+}
+
+// Bool resolves a boolean setting.
+func (c *Config) Bool(name string) (bool, error) {
+	v, err := c.String(name)
+	b, _ := strconv.ParseBool(v)
+	return b, err
+}
+
+// Duration resolves a duration setting.
+func (c *Config) Duration(name string) (time.Duration, error) {
+	v, err := c.String(name)
+	d, _ := time.ParseDuration(v)
+	return d, err
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("search_limit = 20\nrps = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	env := map[string]string{"GROKIR_RPS": "3"}
+	c.lookupEnv = func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantSource Source
+	}{
+		{key: SearchLimit, wantValue: "20", wantSource: SourceFile},
+		{key: RPS, wantValue: "3", wantSource: SourceEnv},
+		{key: CacheTTL, wantValue: "24h0m0s", wantSource: SourceDefault},
+	}
+	for _, tt := range tests {
+		v, src, err := c.Get(tt.key)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", tt.key, err)
+		}
+		if v != tt.wantValue || src != tt.wantSource {
+			t.Errorf("Get(%q) = %q (%s), want %q (%s)", tt.key, v, src, tt.wantValue, tt.wantSource)
+		}
+	}
+
+	if _, _, err := c.Get("nope"); err == nil {
+		t.Error("Get(unknown) expected error, got nil")
+	}
+
+	env["GROKIR_SEARCH_LIMIT"] = "lots"
+	if n, err := c.Int(SearchLimit); n != 10 || err == nil || !strings.Contains(err.Error(), "GROKIR_SEARCH_LIMIT") {
+		t.Errorf("Int() with invalid env = %d, %v; want the default and a mention of GROKIR_SEARCH_LIMIT", n, err)
+	}
+}
+
+func TestConfig_EmptyEnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("proxy = \"http://proxy.example:3128\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	c.lookupEnv = func(k string) (string, bool) { return "", k == "GROKIR_PROXY" }
+
+	if v, src, err := c.Get(Proxy); v != "" || src != SourceEnv || err != nil {
+		t.Errorf("Get(proxy) = %q (%s), %v; want the empty variable to clear the file's proxy", v, src, err)
+	}
+}
+
+func TestConfig_SetSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.toml")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of missing file error = %v", err)
+	}
+	c.lookupEnv = func(string) (string, bool) { return "", false }
+
+	if err := c.Set(CacheTTL, "90m"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Set(Offline, "true"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Set(SearchLimit, "-1"); err == nil {
+		t.Error("Set() with negative limit expected error")
+	}
+	if err := c.Set(Format, "xml"); err == nil {
+		t.Error("Set() with unknown format expected error")
+	}
+	RegisterFormat("xml")
+	if err := c.Set(Format, "xml"); err != nil {
+		t.Errorf("Set() with registered format error = %v", err)
+	}
+	if err := c.Set(BaseURL, "not a url"); err == nil {
+		t.Error("Set() with invalid URL expected error")
+	}
+	if err := c.Set("nope", "1"); err == nil {
+		t.Error("Set() with unknown key expected error")
+	}
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	reloaded.lookupEnv = func(string) (string, bool) { return "", false }
+
+	ttl, err := reloaded.Duration(CacheTTL)
+	if err != nil || ttl != 90*time.Minute {
+		t.Errorf("Duration(cache_ttl) = %v, %v; want 90m", ttl, err)
+	}
+	offline, err := reloaded.Bool(Offline)
+	if err != nil || !offline {
+		t.Errorf("Bool(offline) = %v, %v; want true", offline, err)
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("rps = -2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v; invalid values are reported when read", err)
+	}
+	if v, src, err := c.Get(RPS); v != "-2" || src != SourceFile || err == nil || !strings.Contains(err.Error(), "rps") {
+		t.Errorf("Get(rps) = %q, %q, %v; want the value with an invalid rps error", v, src, err)
+	}
+	if _, err := c.Float(RPS); err == nil {
+		t.Error("Float(rps) = nil error for an invalid value")
+	}
+
+	// The bad value can be replaced.
+	if err := c.Set(RPS, "2"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if rps, err := c.Float(RPS); err != nil || rps != 2 {
+		t.Errorf("Float(rps) after Set = %v, %v; want 2", rps, err)
+	}
+}
+
+func TestDefaultsAreValid(t *testing.T) {
+	for _, k := range Keys() {
+		if err := k.Validate(k.Default); err != nil {
+			t.Errorf("default for %s: %v", k.Name, err)
+		}
+	}
+}
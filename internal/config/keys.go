@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"grokir/internal/grokipedia"
+	"grokir/internal/httpcache"
+)
+
+// formats are the names accepted for the format setting. It starts with
+// the default, which is always valid.
+var formats = []string{"text"}
+
+// RegisterFormat makes name an accepted value of the format setting. It
+// is called as output formats are registered.
+func RegisterFormat(name string) {
+	if !slices.Contains(formats, name) {
+		formats = append(formats, name)
+	}
+}
+
+// Key describes a configuration setting.
+type Key struct {
+	// Name is the key used in the config file and by `grokir config`.
+	Name string
+	// Env is the environment variable that overrides the file.
+	Env string
+	// Default is the value used when neither the environment nor the
+	// file sets the key.
+	Default string
+	// Description is a one-line summary shown by `grokir config list`.
+	Description string
+
+	kind kind
+}
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindURL
+	kindFormat
+	kindInt
+	kindFloat
+	kindBool
+	kindDuration
+)
+
+// Setting names.
+const (
+	BaseURL      = "base_url"
+	UserAgent    = "user_agent"
+	Format       = "format"
+	SearchLimit  = "search_limit"
+	Timeout      = "timeout"
+	Retries      = "retries"
+	RetryMaxWait = "retry_max_wait"
+	RPS          = "rps"
+	Burst        = "burst"
+	Cache        = "cache"
+	CacheTTL     = "cache_ttl"
+	CacheDir     = "cache_dir"
+	Offline      = "offline"
+	Proxy        = "proxy"
+	Pager        = "pager"
+	PagerCommand = "pager_command"
+)
+
+var keys = []Key{
+	{Name: BaseURL, Env: "GROKIR_BASE_URL", Default: grokipedia.DefaultBaseURL, Description: "Grokipedia API base URL", kind: kindURL},
+	{Name: UserAgent, Env: "GROKIR_USER_AGENT", Default: grokipedia.DefaultUserAgent, Description: "User-Agent header sent with requests", kind: kindString},
+	{Name: Format, Env: "GROKIR_FORMAT", Default: "text", Description: "default output format (see grokir formats)", kind: kindFormat},
+	{Name: SearchLimit, Env: "GROKIR_SEARCH_LIMIT", Default: "10", Description: "default number of search results", kind: kindInt},
+	{Name: Timeout, Env: "GROKIR_TIMEOUT", Default: "0s", Description: "overall command timeout (0s for none)", kind: kindDuration},
+	{Name: Retries, Env: "GROKIR_RETRIES", Default: strconv.Itoa(grokipedia.DefaultRetryPolicy().MaxAttempts - 1), Description: "retries for failed requests", kind: kindInt},
+	{Name: RetryMaxWait, Env: "GROKIR_RETRY_MAX_WAIT", Default: grokipedia.DefaultRetryPolicy().MaxDelay.String(), Description: "longest wait between retries", kind: kindDuration},
+	{Name: RPS, Env: "GROKIR_RPS", Default: strconv.FormatFloat(grokipedia.DefaultRate, 'g', -1, 64), Description: "maximum requests per second (0 for no limit)", kind: kindFloat},
+	{Name: Burst, Env: "GROKIR_BURST", Default: strconv.Itoa(grokipedia.DefaultBurst), Description: "requests allowed in a burst", kind: kindInt},
+	{Name: Cache, Env: "GROKIR_CACHE", Default: "true", Description: "use the local response cache", kind: kindBool},
+	{Name: CacheTTL, Env: "GROKIR_CACHE_TTL", Default: httpcache.DefaultTTL.String(), Description: "how long cached responses are reused", kind: kindDuration},
+	{Name: CacheDir, Env: "GROKIR_CACHE_DIR", Default: "", Description: "cache directory (empty for $XDG_CACHE_HOME/grokir)", kind: kindString},
+	{Name: Offline, Env: "GROKIR_OFFLINE", Default: "false", Description: "serve requests only from the local cache", kind: kindBool},
+	{Name: Proxy, Env: "GROKIR_PROXY", Default: "", Description: "HTTP proxy URL (empty to use HTTP(S)_PROXY)", kind: kindURL},
+	{Name: Pager, Env: "GROKIR_PAGER", Default: "true", Description: "page long output on a terminal", kind: kindBool},
+	{Name: PagerCommand, Env: "GROKIR_PAGER_COMMAND", Default: "", Description: "pager command (empty for $PAGER or less -R)", kind: kindString},
+}
+
+// Keys returns every known setting in display order.
+func Keys() []Key {
+	return append([]Key(nil), keys...)
+}
+
+// Lookup returns the setting with the given name.
+func Lookup(name string) (Key, bool) {
+	for _, k := range keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Validate reports whether value is acceptable for the key.
+func (k Key) Validate(value string) error {
+	var err error
+	switch k.kind {
+	case kindURL:
+		if value != "" {
+			var u *url.URL
+			u, err = url.Parse(value)
+			if err == nil && (u.Scheme == "" || u.Host == "") {
+				err = fmt.Errorf("missing scheme or host")
+			}
+		}
+	case kindFormat:
+		if !slices.Contains(formats, value) {
+			err = fmt.Errorf("must be one of: %s", strings.Join(formats, ", "))
+		}
+	case kindInt:
+		var n int
+		n, err = strconv.Atoi(value)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+	case kindFloat:
+		var f float64
+		f, err = strconv.ParseFloat(value, 64)
+		if err == nil && f < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+	case kindBool:
+		_, err = strconv.ParseBool(value)
+	case kindDuration:
+		var d time.Duration
+		d, err = time.ParseDuration(value)
+		if err == nil && d < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for %s: %w", value, k.Name, err)
+	}
+	return nil
+}
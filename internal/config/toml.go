@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parse reads the flat subset of TOML used by grokir config files:
+// `key = value` lines with string, integer, float and boolean values,
+// plus comments. Tables are rejected since no setting needs them.
+func parse(src string) (map[string]string, error) {
+	values := make(map[string]string)
+	for i, line := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			return nil, fmt.Errorf("line %d: tables are not supported", lineNo)
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if !validKey(key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", lineNo, key)
+		}
+		if _, dup := values[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNo, key)
+		}
+
+		value, err := parseValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseValue decodes a single TOML value and returns it as text.
+func parseValue(s string) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("missing value")
+	}
+
+	var value, rest string
+	switch s[0] {
+	case '"':
+		var b strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] != '\\' {
+				b.WriteByte(s[i])
+				continue
+			}
+			i++
+			if i >= len(s) {
+				return "", fmt.Errorf("unterminated string")
+			}
+			switch s[i] {
+			case '"', '\\':
+				b.WriteByte(s[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'u':
+				if i+4 >= len(s) {
+					return "", fmt.Errorf("invalid unicode escape")
+				}
+				n, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", fmt.Errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(n))
+				i += 4
+			default:
+				return "", fmt.Errorf("invalid escape \\%c", s[i])
+			}
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		value, rest = b.String(), s[i+1:]
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		value, rest = s[1:end+1], s[end+2:]
+	default:
+		value, rest, _ = strings.Cut(s, "#")
+		value = strings.TrimSpace(value)
+		rest = ""
+		if value != "true" && value != "false" {
+			if _, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64); err != nil {
+				return "", fmt.Errorf("invalid value %q (strings must be quoted)", value)
+			}
+			value = strings.ReplaceAll(value, "_", "")
+		}
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest != "" && rest[0] != '#' {
+		return "", fmt.Errorf("unexpected %q after value", rest)
+	}
+	return value, nil
+}
+
+// encode renders values for the given keys as TOML. Numeric and boolean
+// settings are written bare; everything else is quoted.
+func encode(names []string, values map[string]string) string {
+	var b strings.Builder
+	b.WriteString("# grokir configuration\n")
+	for _, name := range names {
+		v := values[name]
+		k, known := Lookup(name)
+		bare := known && (k.kind == kindInt || k.kind == kindFloat || k.kind == kindBool)
+		if bare {
+			fmt.Fprintf(&b, "%s = %s\n", name, v)
+		} else {
+			fmt.Fprintf(&b, "%s = %s\n", name, quote(v))
+		}
+	}
+	return b.String()
+}
+
+// quote returns s as a TOML basic string.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f || r == utf8.RuneError:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
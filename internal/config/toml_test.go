@@ -0,0 +1,100 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		want        map[string]string
+		errContains string
+	}{
+		{
+			name: "values and comments",
+			src: `# comment
+base_url = "https://example.test" # trailing
+user_agent = 'my agent'
+search_limit = 25
+rps = 2.5
+offline = true
+
+cache_ttl = "1h"`,
+			want: map[string]string{
+				"base_url":     "https://example.test",
+				"user_agent":   "my agent",
+				"search_limit": "25",
+				"rps":          "2.5",
+				"offline":      "true",
+				"cache_ttl":    "1h",
+			},
+		},
+		{
+			name: "escapes",
+			src:  `user_agent = "a \"quoted\" \\ agenté"`,
+			want: map[string]string{"user_agent": `a "quoted" \ agenté`},
+		},
+		{
+			name: "underscored number",
+			src:  `search_limit = 1_000`,
+			want: map[string]string{"search_limit": "1000"},
+		},
+		{name: "table", src: "[grokir]\nrps = 1", errContains: "line 1: tables are not supported"},
+		{name: "missing equals", src: "rps", errContains: "expected key = value"},
+		{name: "bare string", src: "cache_ttl = 1h", errContains: "strings must be quoted"},
+		{name: "unterminated", src: `user_agent = "abc`, errContains: "unterminated string"},
+		{name: "garbage after value", src: `user_agent = "abc" def`, errContains: "unexpected"},
+		{name: "duplicate", src: "rps = 1\nrps = 2", errContains: "line 2: duplicate key"},
+		{name: "invalid key", src: `bad key = 1`, errContains: "invalid key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.src)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("parse() error = %v, want containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("parse()[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"user_agent":   "agent \"x\"\n\tline",
+		"search_limit": "5",
+		"offline":      "true",
+		"cache_ttl":    "2h",
+	}
+	names := []string{"cache_ttl", "offline", "search_limit", "user_agent"}
+
+	out := encode(names, values)
+	if !strings.Contains(out, "search_limit = 5\n") || !strings.Contains(out, "cache_ttl = \"2h\"\n") {
+		t.Errorf("encode() =\n%s", out)
+	}
+
+	got, err := parse(out)
+	if err != nil {
+		t.Fatalf("parse(encode()) error = %v", err)
+	}
+	for k, v := range values {
+		if got[k] != v {
+			t.Errorf("round trip [%q] = %q, want %q", k, got[k], v)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Package epub writes EPUB 3 books from chapters of XHTML content.
+package epub
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// Paths of the files that make up a book. Chapters live next to the
+// package document so that links between them are plain file names.
+const (
+	mimeType      = "application/epub+zip"
+	containerPath = "META-INF/container.xml"
+	contentDir    = "OEBPS/"
+	packagePath   = contentDir + "content.opf"
+	navFile       = "nav.xhtml"
+	ncxFile       = "toc.ncx"
+	styleFile     = "style.css"
+)
+
+// Book is an EPUB book.
+type Book struct {
+	// Identifier uniquely identifies the book, such as a URN. When
+	// empty, one is derived from the title and chapters, so the same
+	// collection always gets the same identifier.
+	Identifier  string
+	Title       string
+	Language    string
+	Creator     string
+	Description string
+	// Source is the URL the content was taken from.
+	Source string
+	// Modified is the time the book was last changed.
+	Modified time.Time
+	// Stylesheet is CSS applied to every chapter.
+	Stylesheet string
+	Chapters   []Chapter
+}
+
+// Chapter is one content document of a book.
+type Chapter struct {
+	Title string
+	// Body is the XHTML content of the chapter's body element.
+	Body string
+	// Headings are the sections of the chapter listed in the
+	// navigation document under the chapter title.
+	Headings []Heading
+}
+
+// Heading is a section of a chapter. ID is the id attribute of the
+// heading element in the chapter body.
+type Heading struct {
+	Level int
+	Title string
+	ID    string
+}
+
+// ChapterFile returns the file name of the chapter at index i, for links
+// between chapters.
+func ChapterFile(i int) string {
+	return fmt.Sprintf("chapter-%03d.xhtml", i+1)
+}
+
+// Write writes the book to w as an EPUB (zip) archive.
+func (b *Book) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	modified := b.modified()
+
+	// The mimetype file must come first and be stored uncompressed.
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: modified})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, mimeType); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name, content string
+	}{
+		{containerPath, container},
+		{packagePath, b.packageDocument()},
+		{contentDir + navFile, b.navDocument()},
+		{contentDir + ncxFile, b.ncx()},
+		{contentDir + styleFile, b.Stylesheet},
+	}
+	for i, ch := range b.Chapters {
+		files = append(files, struct{ name, content string }{contentDir + ChapterFile(i), b.chapter(ch)})
+	}
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+const container = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="` + packagePath + `" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+func (b *Book) language() string {
+	if b.Language == "" {
+		return "en"
+	}
+	return b.Language
+}
+
+func (b *Book) identifier() string {
+	if b.Identifier != "" {
+		return b.Identifier
+	}
+	h := sha1.New()
+	io.WriteString(h, b.Title)
+	for _, ch := range b.Chapters {
+		io.WriteString(h, "\x00"+ch.Title)
+	}
+	sum := h.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50 // name-based UUID, version 5
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func (b *Book) modified() time.Time {
+	if b.Modified.IsZero() {
+		return time.Now().UTC().Truncate(time.Second)
+	}
+	return b.Modified.UTC()
+}
+
+func (b *Book) packageDocument() string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&s, `<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="%s">`+"\n", esc(b.language()))
+	s.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(&s, "    <dc:identifier id=\"book-id\">%s</dc:identifier>\n", esc(b.identifier()))
+	fmt.Fprintf(&s, "    <dc:title>%s</dc:title>\n", esc(b.Title))
+	fmt.Fprintf(&s, "    <dc:language>%s</dc:language>\n", esc(b.language()))
+	if b.Creator != "" {
+		fmt.Fprintf(&s, "    <dc:creator>%s</dc:creator>\n", esc(b.Creator))
+	}
+	if b.Description != "" {
+		fmt.Fprintf(&s, "    <dc:description>%s</dc:description>\n", esc(b.Description))
+	}
+	if b.Source != "" {
+		fmt.Fprintf(&s, "    <dc:source>%s</dc:source>\n", esc(b.Source))
+	}
+	fmt.Fprintf(&s, "    <meta property=\"dcterms:modified\">%s</meta>\n", b.modified().Format(time.RFC3339))
+	s.WriteString("  </metadata>\n  <manifest>\n")
+	fmt.Fprintf(&s, "    <item id=\"nav\" href=\"%s\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n", navFile)
+	fmt.Fprintf(&s, "    <item id=\"ncx\" href=\"%s\" media-type=\"application/x-dtbncx+xml\"/>\n", ncxFile)
+	fmt.Fprintf(&s, "    <item id=\"style\" href=\"%s\" media-type=\"text/css\"/>\n", styleFile)
+	for i := range b.Chapters {
+		fmt.Fprintf(&s, "    <item id=\"%s\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", chapterID(i), ChapterFile(i))
+	}
+	s.WriteString("  </manifest>\n  <spine toc=\"ncx\">\n")
+	for i := range b.Chapters {
+		fmt.Fprintf(&s, "    <itemref idref=\"%s\"/>\n", chapterID(i))
+	}
+	s.WriteString("  </spine>\n</package>\n")
+	return s.String()
+}
+
+func chapterID(i int) string {
+	return fmt.Sprintf("chapter-%03d", i+1)
+}
+
+// xhtml wraps body in an XHTML content document.
+func (b *Book) xhtml(title, body string) string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n<!DOCTYPE html>\n")
+	fmt.Fprintf(&s, `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%[1]s" lang="%[1]s">`+"\n", esc(b.language()))
+	fmt.Fprintf(&s, "<head>\n<title>%s</title>\n", esc(title))
+	fmt.Fprintf(&s, "<link rel=\"stylesheet\" type=\"text/css\" href=\"%s\"/>\n</head>\n", styleFile)
+	s.WriteString("<body>\n" + body + "</body>\n</html>\n")
+	return s.String()
+}
+
+func (b *Book) chapter(ch Chapter) string {
+	return b.xhtml(ch.Title, ch.Body)
+}
+
+// navDocument returns the EPUB 3 navigation document: every chapter,
+// with its headings nested below it.
+func (b *Book) navDocument() string {
+	var s strings.Builder
+	s.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n")
+	fmt.Fprintf(&s, "<h1>%s</h1>\n<ol>\n", esc(b.Title))
+	for i, ch := range b.Chapters {
+		file := ChapterFile(i)
+		fmt.Fprintf(&s, "<li><a href=\"%s\">%s</a>", file, esc(ch.Title))
+		navHeadings(&s, file, ch.Headings)
+		s.WriteString("</li>\n")
+	}
+	s.WriteString("</ol>\n</nav>\n")
+	return b.xhtml(b.Title, s.String())
+}
+
+// navHeadings writes headings as nested ordered lists. Level 1 headings
+// are skipped, as the chapter entry already stands for them.
+func navHeadings(s *strings.Builder, file string, headings []Heading) {
+	var levels []int
+	for _, h := range headings {
+		if h.Level <= 1 {
+			continue
+		}
+		for len(levels) > 1 && h.Level < levels[len(levels)-1] {
+			s.WriteString("</li>\n</ol>\n")
+			levels = levels[:len(levels)-1]
+		}
+		if len(levels) == 0 || h.Level > levels[len(levels)-1] {
+			s.WriteString("\n<ol>\n")
+			levels = append(levels, h.Level)
+		} else {
+			s.WriteString("</li>\n")
+		}
+		fmt.Fprintf(s, "<li><a href=\"%s#%s\">%s</a>", file, esc(h.ID), esc(h.Title))
+	}
+	for range levels {
+		s.WriteString("</li>\n</ol>\n")
+	}
+}
+
+// ncx returns an EPUB 2 table of contents, for older reading systems.
+func (b *Book) ncx() string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	s.WriteString(`<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">` + "\n")
+	fmt.Fprintf(&s, "<head>\n<meta name=\"dtb:uid\" content=\"%s\"/>\n</head>\n", esc(b.identifier()))
+	fmt.Fprintf(&s, "<docTitle><text>%s</text></docTitle>\n<navMap>\n", esc(b.Title))
+	for i, ch := range b.Chapters {
+		fmt.Fprintf(&s, "<navPoint id=\"nav-%s\" playOrder=\"%d\">\n", chapterID(i), i+1)
+		fmt.Fprintf(&s, "<navLabel><text>%s</text></navLabel>\n<content src=\"%s\"/>\n</navPoint>\n", esc(ch.Title), ChapterFile(i))
+	}
+	s.WriteString("</navMap>\n</ncx>\n")
+	return s.String()
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
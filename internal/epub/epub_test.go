@@ -0,0 +1,156 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBook() *Book {
+	return &Book{
+		Title:      "Go & Rust",
+		Creator:    "Grokipedia",
+		Source:     "https://grokipedia.com",
+		Modified:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Stylesheet: "body { margin: 0; }\n",
+		Chapters: []Chapter{
+			{
+				Title: "Go",
+				Body:  `<h1 id="go">Go</h1>` + "\n" + `<h2 id="history">History</h2>` + "\n" + `<h3 id="early">Early</h3>` + "\n" + `<h2 id="design">Design</h2>` + "\n",
+				Headings: []Heading{
+					{Level: 1, Title: "Go", ID: "go"},
+					{Level: 2, Title: "History", ID: "history"},
+					{Level: 3, Title: "Early", ID: "early"},
+					{Level: 2, Title: "Design", ID: "design"},
+				},
+			},
+			{Title: "Rust", Body: `<p>See <a href="chapter-001.xhtml#history">Go</a>.</p>` + "\n"},
+		},
+	}
+}
+
+func readBook(t *testing.T, b *Book) (*zip.Reader, map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(data)
+	}
+	return zr, files
+}
+
+func TestBook_Write(t *testing.T) {
+	zr, files := readBook(t, testBook())
+
+	first := zr.File[0]
+	if first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want stored mimetype", first.Name, first.Method)
+	}
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("mimetype = %q", files["mimetype"])
+	}
+
+	for _, name := range []string{
+		"META-INF/container.xml",
+		"OEBPS/content.opf",
+		"OEBPS/nav.xhtml",
+		"OEBPS/toc.ncx",
+		"OEBPS/style.css",
+		"OEBPS/chapter-001.xhtml",
+		"OEBPS/chapter-002.xhtml",
+	} {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("missing %s", name)
+			continue
+		}
+		if strings.HasSuffix(name, ".css") {
+			continue
+		}
+		d := xml.NewDecoder(strings.NewReader(content))
+		for {
+			_, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("%s is not well-formed: %v\n%s", name, err, content)
+				break
+			}
+		}
+	}
+
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{
+		`<dc:title>Go &amp; Rust</dc:title>`,
+		`<dc:language>en</dc:language>`,
+		`<dc:creator>Grokipedia</dc:creator>`,
+		`<meta property="dcterms:modified">2025-01-02T03:04:05Z</meta>`,
+		`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>`,
+		`<itemref idref="chapter-001"/>` + "\n" + `    <itemref idref="chapter-002"/>`,
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf missing %q", want)
+		}
+	}
+	if !strings.Contains(opf, `<dc:identifier id="book-id">urn:uuid:`) {
+		t.Errorf("content.opf has no derived identifier:\n%s", opf)
+	}
+}
+
+func TestBook_Nav(t *testing.T) {
+	_, files := readBook(t, testBook())
+	nav := files["OEBPS/nav.xhtml"]
+	want := `<li><a href="chapter-001.xhtml">Go</a>
+<ol>
+<li><a href="chapter-001.xhtml#history">History</a>
+<ol>
+<li><a href="chapter-001.xhtml#early">Early</a></li>
+</ol>
+</li>
+<li><a href="chapter-001.xhtml#design">Design</a></li>
+</ol>
+</li>
+<li><a href="chapter-002.xhtml">Rust</a></li>`
+	if !strings.Contains(nav, want) {
+		t.Errorf("nav.xhtml =\n%s\nwant it to contain\n%s", nav, want)
+	}
+	if !strings.Contains(nav, `<nav epub:type="toc" id="toc">`) {
+		t.Errorf("nav.xhtml has no toc nav")
+	}
+}
+
+func TestBook_Identifier(t *testing.T) {
+	a, b := testBook(), testBook()
+	if a.identifier() != b.identifier() {
+		t.Errorf("identifier() differs for the same book")
+	}
+	b.Chapters = b.Chapters[:1]
+	if a.identifier() == b.identifier() {
+		t.Errorf("identifier() is the same for different books")
+	}
+	b.Identifier = "isbn:123"
+	if got := b.identifier(); got != "isbn:123" {
+		t.Errorf("identifier() = %q, want the set identifier", got)
+	}
+}
@@ -0,0 +1,123 @@
+package grokipedia
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// DefaultConcurrency is the number of workers GetPages uses when
+// GetPagesOptions.Concurrency is not set.
+const DefaultConcurrency = 4
+
+// GetPagesOptions controls how GetPages fetches pages.
+type GetPagesOptions struct {
+	// Concurrency is the number of pages fetched in parallel.
+	Concurrency int
+	// RatePerSecond limits how many requests this call starts per
+	// second across all workers, on top of any limiter installed in the
+	// client's transport. Zero means no additional limit.
+	RatePerSecond float64
+	// IncludeContent and ValidateLinks are passed to GetPageContext.
+	IncludeContent bool
+	ValidateLinks  bool
+}
+
+// PageResult is the outcome of fetching a single slug with GetPages.
+// Exactly one of Page and Err is set.
+type PageResult struct {
+	Slug string
+	Page *Page
+	Err  error
+}
+
+// GetPages fetches every slug using a bounded pool of workers. Results
+// are returned in the order of slugs; a failure for one slug is recorded
+// in its PageResult and does not stop the others. Slugs not yet started
+// when ctx is done fail with the context error.
+func (c *Client) GetPages(ctx context.Context, slugs []string, opts GetPagesOptions) []PageResult {
+	results := make([]PageResult, 0, len(slugs))
+	for r := range c.GetPagesSeq(ctx, slugs, opts) {
+		results = append(results, r)
+	}
+	return results
+}
+
+// GetPagesSeq is like GetPages, but returns an iterator that yields each
+// result as soon as it and the results before it are done, so callers
+// can use pages while later ones are still being fetched. Stopping the
+// iteration cancels the remaining fetches.
+func (c *Client) GetPagesSeq(ctx context.Context, slugs []string, opts GetPagesOptions) iter.Seq[PageResult] {
+	return func(yield func(PageResult) bool) {
+		if len(slugs) == 0 {
+			return
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		workers := opts.Concurrency
+		if workers <= 0 {
+			workers = DefaultConcurrency
+		}
+		workers = min(workers, len(slugs))
+
+		var limiter *RateLimiter
+		if opts.RatePerSecond > 0 {
+			limiter = NewRateLimiter(opts.RatePerSecond, 1, nil)
+		}
+
+		// Each slug gets a buffered channel for its result, so neither
+		// the workers nor the dispatcher block on a slow consumer.
+		done := make([]chan PageResult, len(slugs))
+		for i := range done {
+			done[i] = make(chan PageResult, 1)
+		}
+
+		jobs := make(chan int)
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					if err := limiter.Wait(ctx); err != nil {
+						done[i] <- PageResult{Slug: slugs[i], Err: err}
+						continue
+					}
+					done[i] <- c.fetchPage(ctx, slugs[i], opts)
+				}
+			}()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(jobs)
+			for i, slug := range slugs {
+				if err := ctx.Err(); err != nil {
+					done[i] <- PageResult{Slug: slug, Err: err}
+					continue
+				}
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					done[i] <- PageResult{Slug: slug, Err: ctx.Err()}
+				}
+			}
+		}()
+
+		for _, ch := range done {
+			if !yield(<-ch) {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) fetchPage(ctx context.Context, slug string, opts GetPagesOptions) PageResult {
+	page, err := c.GetPageContext(ctx, slug, opts.IncludeContent, opts.ValidateLinks)
+	return PageResult{Slug: slug, Page: page, Err: err}
+}
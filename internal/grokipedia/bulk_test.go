@@ -0,0 +1,134 @@
+package grokipedia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_GetPages(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		slug := r.URL.Query().Get("slug")
+		if slug == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(pageResponse{Found: true, Page: &Page{Slug: slug, Title: "Title " + slug}})
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+	slugs := []string{"a", "b", "missing", "c", "d", "e"}
+
+	results := client.GetPages(context.Background(), slugs, GetPagesOptions{Concurrency: 2, IncludeContent: true})
+
+	if len(results) != len(slugs) {
+		t.Fatalf("got %d results, want %d", len(results), len(slugs))
+	}
+	for i, r := range results {
+		if r.Slug != slugs[i] {
+			t.Errorf("results[%d].Slug = %q, want %q", i, r.Slug, slugs[i])
+		}
+		if r.Slug == "missing" {
+			if !errors.Is(r.Err, ErrNotFound) {
+				t.Errorf("results[%d].Err = %v, want ErrNotFound", i, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("results[%d].Err = %v", i, r.Err)
+			continue
+		}
+		if r.Page == nil || r.Page.Slug != slugs[i] {
+			t.Errorf("results[%d].Page = %+v, want slug %q", i, r.Page, slugs[i])
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", p)
+	}
+}
+
+func TestClient_GetPagesRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(pageResponse{Found: true, Page: &Page{Slug: r.URL.Query().Get("slug")}})
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+
+	start := time.Now()
+	results := client.GetPages(context.Background(), []string{"a", "b", "c", "d"}, GetPagesOptions{Concurrency: 4, RatePerSecond: 50})
+	elapsed := time.Since(start)
+
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatalf("%s: unexpected error: %v", r.Slug, r.Err)
+		}
+	}
+	if want := 3 * 20 * time.Millisecond; elapsed < want {
+		t.Errorf("elapsed = %v, want at least %v", elapsed, want)
+	}
+}
+
+func TestClient_GetPagesCancelled(t *testing.T) {
+	client := &Client{BaseURL: "http://127.0.0.1:0"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := client.GetPages(ctx, []string{"a", "b"}, GetPagesOptions{})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: Err = %v, want context.Canceled", r.Slug, r.Err)
+		}
+	}
+}
+
+func TestClient_GetPagesSeq(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slug := r.URL.Query().Get("slug")
+		if slug == "slow" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(pageResponse{Found: true, Page: &Page{Slug: slug}})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+
+	// The first result arrives while a later page is still pending, and
+	// stopping early cancels it.
+	var got []string
+	for r := range client.GetPagesSeq(context.Background(), []string{"a", "slow"}, GetPagesOptions{Concurrency: 2}) {
+		if r.Err != nil {
+			t.Fatalf("%s: unexpected error: %v", r.Slug, r.Err)
+		}
+		got = append(got, r.Slug)
+		break
+	}
+	if len(got) != 1 || got[0] != "a" {
+		t.Errorf("got %v, want [a]", got)
+	}
+}
@@ -1,15 +1,25 @@
 package grokipedia
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+// Defaults used by NewClient.
 const (
-	defaultBaseURL   = "https://grokipedia.com"
-	defaultUserAgent = "grokir/0.1 (Go Grokipedia CLI)"
+	DefaultBaseURL   = "https://grokipedia.com"
+	DefaultUserAgent = "grokir/0.1 (Go Grokipedia CLI)"
+)
+
+// API paths, relative to the base URL.
+const (
+	SearchEndpoint = "/api/full-text-search"
+	PageEndpoint   = "/api/page"
 )
 
 // Client is a minimal HTTP client for the Grokipedia REST API.
@@ -17,24 +27,33 @@ type Client struct {
 	BaseURL   string
 	UserAgent string
 	HTTP      *http.Client
+	Retry     RetryPolicy
+
+	// sleep waits between retry attempts; tests replace it to observe
+	// delays without actually waiting.
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
+// NewClient returns a client for the public Grokipedia API whose
+// requests are throttled to DefaultRate per second.
 func NewClient() *Client {
+	limiter := NewRateLimiter(DefaultRate, DefaultBurst, nil)
 	return &Client{
-		BaseURL:   defaultBaseURL,
-		UserAgent: defaultUserAgent,
-		HTTP:      http.DefaultClient,
+		BaseURL:   DefaultBaseURL,
+		UserAgent: DefaultUserAgent,
+		HTTP:      &http.Client{Transport: limiter.Transport(http.DefaultTransport)},
+		Retry:     DefaultRetryPolicy(),
 	}
 }
 
 // SearchResult matches the structure returned by /api/full-text-search.
 type SearchResult struct {
-	Slug            string   `json:"slug"`
-	Title           string   `json:"title"`
-	Snippet         string   `json:"snippet"`
-	RelevanceScore  float64  `json:"relevance_score"`
-	ViewCount       int64    `json:"view_count,string"`
-	TitleHighlights []string `json:"title_highlights"`
+	Slug              string   `json:"slug"`
+	Title             string   `json:"title"`
+	Snippet           string   `json:"snippet"`
+	RelevanceScore    float64  `json:"relevance_score"`
+	ViewCount         int64    `json:"view_count,string"`
+	TitleHighlights   []string `json:"title_highlights"`
 	SnippetHighlights []string `json:"snippet_highlights"`
 }
 
@@ -42,32 +61,77 @@ type searchResponse struct {
 	Results []SearchResult `json:"results"`
 }
 
-// Page represents a Grokipedia page from /api/page.
-type Page struct {
-	Title       string `json:"title"`
-	Slug        string `json:"slug"`
-	Description string `json:"description"`
-	Content     string `json:"content"`
-}
-
 type pageResponse struct {
 	Found bool  `json:"found"`
 	Page  *Page `json:"page"`
 }
 
-// Search performs a full-text search on Grokipedia.
-func (c *Client) Search(query string, limit, offset int) ([]SearchResult, error) {
-	if c.HTTP == nil {
-		c.HTTP = http.DefaultClient
-	}
-
+// newRequest builds a GET request for the given API path and query,
+// bound to ctx so that it can be cancelled or time-bounded.
+func (c *Client) newRequest(ctx context.Context, path string, q url.Values) (*http.Request, error) {
 	u, err := url.Parse(c.BaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
-	u.Path = "/api/full-text-search"
+	u.Path = path
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	return req, nil
+}
 
-	q := u.Query()
+// do sends req and returns the response, retrying transport errors and
+// transient statuses according to c.Retry. If the request failed because
+// its context was cancelled or expired, the context error is returned
+// as-is so callers can test for it with errors.Is.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.HTTP == nil {
+		c.HTTP = http.DefaultClient
+	}
+	sleep := c.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	attempts := c.Retry.attempts(req)
+	for attempt := 1; ; attempt++ {
+		var wait time.Duration
+
+		resp, err := c.HTTP.Do(req)
+		switch {
+		case err != nil:
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if attempt >= attempts {
+				return nil, fmt.Errorf("performing request: %w", &networkError{err: err})
+			}
+			wait = c.Retry.backoff(attempt)
+		case attempt >= attempts || !retryableStatus(resp.StatusCode):
+			return resp, nil
+		default:
+			wait = c.Retry.delay(attempt, resp)
+			discard(resp)
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Search performs a full-text search on Grokipedia.
+func (c *Client) Search(query string, limit, offset int) ([]SearchResult, error) {
+	return c.SearchContext(context.Background(), query, limit, offset)
+}
+
+// SearchContext is like Search but aborts the request when ctx is done.
+func (c *Client) SearchContext(ctx context.Context, query string, limit, offset int) ([]SearchResult, error) {
+	q := url.Values{}
 	q.Set("query", query)
 	if limit > 0 {
 		q.Set("limit", fmt.Sprint(limit))
@@ -75,22 +139,20 @@ func (c *Client) Search(query string, limit, offset int) ([]SearchResult, error)
 	if offset > 0 {
 		q.Set("offset", fmt.Sprint(offset))
 	}
-	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	req, err := c.newRequest(ctx, SearchEndpoint, q)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", c.UserAgent)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("performing request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("search failed: %s", resp.Status)
+		return nil, fmt.Errorf("search failed: %w", newAPIError(req, resp, SearchEndpoint))
 	}
 
 	var sr searchResponse
@@ -103,48 +165,59 @@ func (c *Client) Search(query string, limit, offset int) ([]SearchResult, error)
 
 // GetPage retrieves a page by slug.
 func (c *Client) GetPage(slug string, includeContent, validateLinks bool) (*Page, error) {
-	if c.HTTP == nil {
-		c.HTTP = http.DefaultClient
-	}
-
-	u, err := url.Parse(c.BaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %w", err)
-	}
-	u.Path = "/api/page"
+	return c.GetPageContext(context.Background(), slug, includeContent, validateLinks)
+}
 
-	q := u.Query()
+// GetPageContext is like GetPage but aborts the request when ctx is done.
+func (c *Client) GetPageContext(ctx context.Context, slug string, includeContent, validateLinks bool) (*Page, error) {
+	q := url.Values{}
 	q.Set("slug", slug)
 	q.Set("includeContent", fmt.Sprintf("%v", includeContent))
 	q.Set("validateLinks", fmt.Sprintf("%v", validateLinks))
-	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	req, err := c.newRequest(ctx, PageEndpoint, q)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("User-Agent", c.UserAgent)
 
-	resp, err := c.HTTP.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("performing request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("page not found: %s", slug)
+		return nil, fmt.Errorf("page not found: %s: %w", slug, newAPIError(req, resp, PageEndpoint))
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get page failed: %s", resp.Status)
+		return nil, fmt.Errorf("get page failed: %w", newAPIError(req, resp, PageEndpoint))
+	}
+
+	page, err := decodePageResponse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if page == nil {
+		return nil, fmt.Errorf("page not found: %s: %w", slug, &APIError{
+			StatusCode: http.StatusNotFound,
+			Endpoint:   PageEndpoint,
+			URL:        req.URL.String(),
+			Slug:       slug,
+		})
 	}
 
+	return page, nil
+}
+
+// decodePageResponse decodes a PageEndpoint response body. The page is
+// nil when the response reports that it does not exist.
+func decodePageResponse(r io.Reader) (*Page, error) {
 	var pr pageResponse
-	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+	if err := json.NewDecoder(r).Decode(&pr); err != nil {
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
-	if !pr.Found || pr.Page == nil {
-		return nil, fmt.Errorf("page not found: %s", slug)
+	if !pr.Found {
+		return nil, nil
 	}
-
 	return pr.Page, nil
 }
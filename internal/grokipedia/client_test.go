@@ -1,11 +1,14 @@
 package grokipedia
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestClient_Search(t *testing.T) {
@@ -234,3 +237,41 @@ func TestClient_GetPage(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_ContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &Client{
+		BaseURL:   server.URL,
+		UserAgent: "grokir-test-agent",
+		HTTP:      server.Client(),
+	}
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.SearchContext(ctx, "kubernetes", 10, 0)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("SearchContext() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		_, err := client.GetPageContext(ctx, "kubernetes", true, false)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("GetPageContext() error = %v, want context.Canceled", err)
+		}
+	})
+}
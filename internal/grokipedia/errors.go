@@ -0,0 +1,84 @@
+package grokipedia
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// Sentinel errors classifying API failures. Use errors.Is to test for
+// them; the concrete error is usually an *APIError.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrRateLimited = errors.New("rate limited")
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// maxErrorBody bounds how much of an error response body is kept.
+const maxErrorBody = 512
+
+// APIError describes a non-successful response from the Grokipedia API.
+type APIError struct {
+	// StatusCode is the HTTP status of the response. A page reported as
+	// missing with found=false is recorded as http.StatusNotFound.
+	StatusCode int
+	// Endpoint is the API path that was called, e.g. "/api/page".
+	Endpoint string
+	// URL is the full request URL.
+	URL string
+	// Slug is the page that was requested, for page requests.
+	Slug string
+	// Body holds the start of the response body, truncated to 512 bytes.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s from %s", e.StatusCode, http.StatusText(e.StatusCode), e.Endpoint)
+}
+
+// Is reports whether the error belongs to the class of target, so that
+// errors.Is(err, ErrNotFound) matches a 404 *APIError.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUnavailable:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// newAPIError builds an *APIError from the response to req, consuming
+// part of its body. The request is passed in rather than taken from
+// resp.Request, which a RoundTripper need not set.
+func newAPIError(req *http.Request, resp *http.Response, endpoint string) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	for len(body) > 0 && !utf8.Valid(body) {
+		body = body[:len(body)-1]
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Endpoint:   endpoint,
+		URL:        req.URL.String(),
+		Slug:       req.URL.Query().Get("slug"),
+		Body:       string(body),
+	}
+}
+
+// networkError marks a transport failure as ErrUnavailable while
+// keeping the underlying error inspectable.
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *networkError) Unwrap() []error {
+	return []error{e.err, ErrUnavailable}
+}
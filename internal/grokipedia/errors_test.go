@@ -0,0 +1,171 @@
+package grokipedia
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestClient_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(c *Client) error
+		statusCode int
+		response   string
+		wantIs     error
+		wantNotIs  []error
+		wantStatus int
+		wantPath   string
+	}{
+		{
+			name:       "page 404",
+			call:       func(c *Client) error { _, err := c.GetPage("missing", true, false); return err },
+			statusCode: http.StatusNotFound,
+			response:   "no such page",
+			wantIs:     ErrNotFound,
+			wantNotIs:  []error{ErrRateLimited, ErrUnavailable},
+			wantStatus: http.StatusNotFound,
+			wantPath:   "/api/page",
+		},
+		{
+			name:       "page found=false",
+			call:       func(c *Client) error { _, err := c.GetPage("deleted", true, false); return err },
+			statusCode: http.StatusOK,
+			response:   `{"found":false,"page":null}`,
+			wantIs:     ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantPath:   "/api/page",
+		},
+		{
+			name:       "search rate limited",
+			call:       func(c *Client) error { _, err := c.Search("x", 10, 0); return err },
+			statusCode: http.StatusTooManyRequests,
+			response:   "slow down",
+			wantIs:     ErrRateLimited,
+			wantNotIs:  []error{ErrNotFound, ErrUnavailable},
+			wantStatus: http.StatusTooManyRequests,
+			wantPath:   "/api/full-text-search",
+		},
+		{
+			name:       "search unavailable",
+			call:       func(c *Client) error { _, err := c.Search("x", 10, 0); return err },
+			statusCode: http.StatusServiceUnavailable,
+			response:   strings.Repeat("x", 2*maxErrorBody),
+			wantIs:     ErrUnavailable,
+			wantNotIs:  []error{ErrNotFound, ErrRateLimited},
+			wantStatus: http.StatusServiceUnavailable,
+			wantPath:   "/api/full-text-search",
+		},
+		{
+			name:       "page bad request",
+			call:       func(c *Client) error { _, err := c.GetPage("bad", true, false); return err },
+			statusCode: http.StatusBadRequest,
+			response:   "bad slug",
+			wantNotIs:  []error{ErrNotFound, ErrRateLimited, ErrUnavailable},
+			wantStatus: http.StatusBadRequest,
+			wantPath:   "/api/page",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+			err := tt.call(client)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.wantIs)
+			}
+			for _, target := range tt.wantNotIs {
+				if errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = true, want false", err, target)
+				}
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("errors.As(%v, *APIError) = false", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+			}
+			if apiErr.Endpoint != tt.wantPath {
+				t.Errorf("Endpoint = %q, want %q", apiErr.Endpoint, tt.wantPath)
+			}
+			if !strings.HasPrefix(apiErr.URL, server.URL+tt.wantPath+"?") {
+				t.Errorf("URL = %q, want prefix %q", apiErr.URL, server.URL+tt.wantPath)
+			}
+			if u, err := url.Parse(apiErr.URL); err != nil || apiErr.Slug != u.Query().Get("slug") {
+				t.Errorf("Slug = %q, want the slug requested in %q", apiErr.Slug, apiErr.URL)
+			}
+			if tt.wantPath == "/api/page" && apiErr.Slug == "" {
+				t.Error("Slug is empty for a page request")
+			}
+			if len(apiErr.Body) > maxErrorBody {
+				t.Errorf("Body has %d bytes, want at most %d", len(apiErr.Body), maxErrorBody)
+			}
+		})
+	}
+}
+
+// bareTransport answers every request with a response that, unlike those
+// of net/http, has no Request set.
+type bareTransport struct {
+	status int
+}
+
+func (t bareTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: t.status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("missing")),
+	}, nil
+}
+
+func TestClient_ErrorWithoutResponseRequest(t *testing.T) {
+	client := &Client{BaseURL: "http://grokipedia.test", HTTP: &http.Client{Transport: bareTransport{http.StatusNotFound}}}
+
+	_, err := client.GetPage("go", true, false)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetPage() error = %v, want an *APIError", err)
+	}
+	if apiErr.Slug != "go" || !strings.HasPrefix(apiErr.URL, "http://grokipedia.test/api/page?") {
+		t.Errorf("APIError = %+v, want the page request", apiErr)
+	}
+
+	if _, err := client.Search("go", 10, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Search() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClient_NetworkErrorIsUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	client := &Client{BaseURL: baseURL}
+	_, err := client.Search("kubernetes", 10, 0)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("errors.Is(%v, ErrUnavailable) = false, want true", err)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Errorf("transport error should not be an *APIError: %v", err)
+	}
+}
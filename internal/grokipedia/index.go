@@ -0,0 +1,271 @@
+package grokipedia
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"grokir/internal/markdown"
+)
+
+// snippetRadius is the number of characters kept on each side of the
+// first match when building a local search snippet.
+const snippetRadius = 100
+
+// DecodePage decodes a raw PageEndpoint response body, as stored by a
+// response cache. It returns an error wrapping ErrNotFound when the
+// response reports that the page does not exist.
+func DecodePage(data []byte) (*Page, error) {
+	page, err := decodePageResponse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if page == nil {
+		return nil, ErrNotFound
+	}
+	return page, nil
+}
+
+// CacheableResponse reports whether a successful API response to req
+// may be stored by a response cache. A page response reporting that the
+// page does not exist is not, so that a page created later is found.
+func CacheableResponse(req *http.Request, body []byte) bool {
+	if req.URL.Path != PageEndpoint {
+		return true
+	}
+	_, err := DecodePage(body)
+	return !errors.Is(err, ErrNotFound)
+}
+
+// Index is an in-memory full-text index over a set of pages. It backs
+// search when the API cannot be reached.
+type Index struct {
+	docs []indexDoc
+}
+
+type indexDoc struct {
+	page    *Page
+	title   map[string]int
+	desc    map[string]int
+	content map[string]int
+}
+
+// NewIndex builds an index over pages. When several pages share a slug,
+// the one with the most content is kept.
+func NewIndex(pages []*Page) *Index {
+	bySlug := make(map[string]*Page)
+	var order []string
+	for _, p := range pages {
+		if p == nil {
+			continue
+		}
+		prev, ok := bySlug[p.Slug]
+		if !ok {
+			order = append(order, p.Slug)
+		}
+		if !ok || len(p.Content) > len(prev.Content) {
+			bySlug[p.Slug] = p
+		}
+	}
+
+	ix := &Index{}
+	for _, slug := range order {
+		p := bySlug[slug]
+		ix.docs = append(ix.docs, indexDoc{
+			page:    p,
+			title:   termCounts(p.Title),
+			desc:    termCounts(p.Description),
+			content: termCounts(p.Content),
+		})
+	}
+	return ix
+}
+
+// Len returns the number of indexed pages.
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Search ranks indexed pages against query. Pages matching more distinct
+// query terms rank first; ties are broken by a weighted term frequency
+// favouring title and description matches. RelevanceScore is normalized
+// to the best match.
+func (ix *Index) Search(query string, limit, offset int) []SearchResult {
+	terms := uniqueTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		doc     *indexDoc
+		matched int
+		score   float64
+	}
+	var hits []hit
+	for i := range ix.docs {
+		d := &ix.docs[i]
+		h := hit{doc: d}
+		for _, t := range terms {
+			n := 5*d.title[t] + 2*d.desc[t] + d.content[t]
+			if n > 0 {
+				h.matched++
+				h.score += float64(n)
+			}
+		}
+		if h.matched > 0 {
+			hits = append(hits, h)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].matched != hits[j].matched {
+			return hits[i].matched > hits[j].matched
+		}
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].doc.page.Title < hits[j].doc.page.Title
+	})
+
+	if offset > 0 {
+		if offset >= len(hits) {
+			return nil
+		}
+		hits = hits[offset:]
+	}
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	var best float64
+	for _, h := range hits {
+		best = max(best, h.score)
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, h := range hits {
+		p := h.doc.page
+		text := p.Content
+		if text == "" {
+			text = p.Description
+		}
+		snippet := snippetAround(plainText(text), terms)
+		results = append(results, SearchResult{
+			Slug:              p.Slug,
+			Title:             p.Title,
+			Snippet:           snippet,
+			RelevanceScore:    h.score / best,
+			TitleHighlights:   matchedTerms(p.Title, terms),
+			SnippetHighlights: matchedTerms(snippet, terms),
+		})
+	}
+	return results
+}
+
+// tokenize splits s into lowercase words.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func termCounts(s string) map[string]int {
+	counts := make(map[string]int)
+	for _, t := range tokenize(s) {
+		counts[t]++
+	}
+	return counts
+}
+
+func uniqueTerms(s string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, t := range tokenize(s) {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// matchedTerms returns the terms that occur as words in s.
+func matchedTerms(s string, terms []string) []string {
+	counts := termCounts(s)
+	var out []string
+	for _, t := range terms {
+		if counts[t] > 0 {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// plainText returns the text of the markdown document src, without
+// markup.
+func plainText(src string) string {
+	var parts []string
+	eachInlineText(markdown.Parse(src), func(s string) {
+		parts = append(parts, markdown.PlainText(markdown.ParseInline(s)))
+	})
+	return strings.Join(parts, " ")
+}
+
+// snippetAround returns a whitespace-normalized excerpt of text centered
+// on the first occurrence of any term, cut at word boundaries.
+func snippetAround(text string, terms []string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := []rune(strings.ToLower(string(runes)))
+
+	first, firstEnd := -1, 0
+	for _, t := range terms {
+		if i := runeIndex(lower, []rune(t)); i >= 0 && (first < 0 || i < first) {
+			first, firstEnd = i, i+len([]rune(t))
+		}
+	}
+	if first < 0 {
+		first, firstEnd = 0, 0
+	}
+
+	start := max(0, first-snippetRadius)
+	end := min(len(runes), first+snippetRadius)
+	// Cut at word boundaries, without losing the match.
+	if i := slices.Index(runes[start:first], ' '); start > 0 && i >= 0 {
+		start += i + 1
+	}
+	if end < len(runes) && end > firstEnd {
+		if i := lastIndexRune(runes[firstEnd:end], ' '); i >= 0 {
+			end = firstEnd + i
+		}
+	}
+	snippet := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+func lastIndexRune(s []rune, r rune) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if string(s[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+	return -1
+}
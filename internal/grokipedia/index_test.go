@@ -0,0 +1,130 @@
+package grokipedia
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDecodePage(t *testing.T) {
+	page, err := DecodePage([]byte(`{"found":true,"page":{"title":"Kubernetes","slug":"kubernetes"}}`))
+	if err != nil {
+		t.Fatalf("DecodePage() error = %v", err)
+	}
+	if page.Slug != "kubernetes" {
+		t.Errorf("Slug = %q, want %q", page.Slug, "kubernetes")
+	}
+
+	if _, err := DecodePage([]byte(`{"found":false,"page":null}`)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DecodePage(found=false) error = %v, want ErrNotFound", err)
+	}
+	if _, err := DecodePage([]byte(`{`)); err == nil {
+		t.Error("DecodePage(invalid) expected error, got nil")
+	}
+}
+
+func TestCacheableResponse(t *testing.T) {
+	tests := []struct {
+		path string
+		body string
+		want bool
+	}{
+		{PageEndpoint, `{"found":true,"page":{"title":"Go","slug":"Go"}}`, true},
+		{PageEndpoint, `{"found":false,"page":null}`, false},
+		{SearchEndpoint, `{"results":[]}`, true},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "https://grokipedia.com"+tt.path, nil)
+		if got := CacheableResponse(req, []byte(tt.body)); got != tt.want {
+			t.Errorf("CacheableResponse(%s, %s) = %v, want %v", tt.path, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	pages := []*Page{
+		{Slug: "kubernetes", Title: "Kubernetes", Description: "Container orchestration", Content: "Kubernetes schedules containers onto nodes."},
+		{Slug: "docker", Title: "Docker", Description: "Container runtime", Content: "Docker builds container images."},
+		{Slug: "scheduler", Title: "Kubernetes Scheduler", Content: "The scheduler places pods. " + strings.Repeat("filler ", 60) + "Kubernetes scheduler details."},
+		{Slug: "docker", Title: "Docker", Content: "short"},
+		nil,
+	}
+	ix := NewIndex(pages)
+
+	if got := ix.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		limit     int
+		offset    int
+		wantSlugs []string
+	}{
+		{name: "all terms rank first", query: "kubernetes scheduler", wantSlugs: []string{"scheduler", "kubernetes"}},
+		{name: "case insensitive", query: "CONTAINER", wantSlugs: []string{"docker", "kubernetes"}},
+		{name: "limit", query: "container", limit: 1, wantSlugs: []string{"docker"}},
+		{name: "offset", query: "container", offset: 1, wantSlugs: []string{"kubernetes"}},
+		{name: "offset past end", query: "container", offset: 5, wantSlugs: nil},
+		{name: "no match", query: "zebra", wantSlugs: nil},
+		{name: "empty query", query: "  ", wantSlugs: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := ix.Search(tt.query, tt.limit, tt.offset)
+			var slugs []string
+			for _, r := range results {
+				slugs = append(slugs, r.Slug)
+			}
+			if strings.Join(slugs, ",") != strings.Join(tt.wantSlugs, ",") {
+				t.Errorf("Search(%q) slugs = %v, want %v", tt.query, slugs, tt.wantSlugs)
+			}
+			if len(results) > 0 && tt.offset == 0 && results[0].RelevanceScore != 1 {
+				t.Errorf("top RelevanceScore = %v, want 1", results[0].RelevanceScore)
+			}
+		})
+	}
+
+	t.Run("snippet and highlights", func(t *testing.T) {
+		results := ix.Search("details", 0, 0)
+		if len(results) != 1 {
+			t.Fatalf("got %d results, want 1", len(results))
+		}
+		r := results[0]
+		if !strings.Contains(r.Snippet, "Kubernetes scheduler details.") || !strings.HasPrefix(r.Snippet, "...") {
+			t.Errorf("Snippet = %q, want excerpt around match", r.Snippet)
+		}
+		if len(r.SnippetHighlights) != 1 || r.SnippetHighlights[0] != "details" {
+			t.Errorf("SnippetHighlights = %v, want [details]", r.SnippetHighlights)
+		}
+		if len(r.TitleHighlights) != 0 {
+			t.Errorf("TitleHighlights = %v, want none", r.TitleHighlights)
+		}
+	})
+	t.Run("snippet from markdown", func(t *testing.T) {
+		ix := NewIndex([]*Page{{
+			Slug:    "raft",
+			Title:   "Raft",
+			Content: "# Raft\n\n" + strings.Repeat("consensus ", 30) + "**Raft** elects a [leader](/page/Leader) by " + strings.Repeat("majority ", 30) + "vote.\n",
+		}})
+		results := ix.Search("leader", 0, 0)
+		if len(results) != 1 {
+			t.Fatalf("got %d results, want 1", len(results))
+		}
+		snippet := results[0].Snippet
+		for _, markup := range []string{"**", "#", "[", "](/page"} {
+			if strings.Contains(snippet, markup) {
+				t.Errorf("Snippet = %q, contains markup %q", snippet, markup)
+			}
+		}
+		if !strings.Contains(snippet, "Raft elects a leader by") {
+			t.Errorf("Snippet = %q, want the plain text around the match", snippet)
+		}
+		if !strings.HasPrefix(snippet, "...consensus ") || !strings.HasSuffix(snippet, " majority...") {
+			t.Errorf("Snippet = %q, want it cut at word boundaries", snippet)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package grokipedia
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultSearchPageSize is the number of results SearchAll requests per
+// call when SearchOptions.PageSize is not set.
+const DefaultSearchPageSize = 50
+
+// SearchOptions controls how SearchAll walks result pages.
+type SearchOptions struct {
+	// PageSize is the number of results requested per call.
+	PageSize int
+	// Offset is the number of leading results to skip.
+	Offset int
+	// Max stops iteration after this many results. Zero means no limit.
+	Max int
+}
+
+// SearchAll returns an iterator over every result for query, fetching
+// successive pages on demand until the results are exhausted, opts.Max
+// is reached or the caller stops iterating. The results are exhausted
+// when a page is empty or brings no new results; a short page does not
+// end the iteration, since the API may cap the page size below the
+// limit asked for. A request error is yielded once with a zero
+// SearchResult and ends the iteration.
+func (c *Client) SearchAll(ctx context.Context, query string, opts SearchOptions) iter.Seq2[SearchResult, error] {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultSearchPageSize
+	}
+
+	return func(yield func(SearchResult, error) bool) {
+		offset := opts.Offset
+		seen := make(map[string]bool)
+		count := 0
+
+		for {
+			limit := pageSize
+			if opts.Max > 0 {
+				limit = min(limit, opts.Max-count)
+			}
+
+			results, err := c.SearchContext(ctx, query, limit, offset)
+			if err != nil {
+				yield(SearchResult{}, err)
+				return
+			}
+
+			fresh := 0
+			for _, r := range results {
+				// Guard against overlapping pages if results shift
+				// between requests.
+				if seen[r.Slug] {
+					continue
+				}
+				seen[r.Slug] = true
+				fresh++
+				count++
+				if !yield(r, nil) {
+					return
+				}
+				if opts.Max > 0 && count >= opts.Max {
+					return
+				}
+			}
+
+			if fresh == 0 {
+				return
+			}
+			offset += len(results)
+		}
+	}
+}
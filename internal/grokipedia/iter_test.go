@@ -0,0 +1,124 @@
+package grokipedia
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// newPagedSearchServer serves total results, honoring limit and offset.
+// When maxLimit is positive, pages hold at most maxLimit results
+// whatever the limit asked for.
+func newPagedSearchServer(t *testing.T, total, maxLimit int, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if maxLimit > 0 {
+			limit = min(limit, maxLimit)
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		var sr searchResponse
+		for i := offset; i < total && i < offset+limit; i++ {
+			sr.Results = append(sr.Results, SearchResult{Slug: "slug-" + strconv.Itoa(i), Title: "Result " + strconv.Itoa(i)})
+		}
+		if sr.Results == nil {
+			sr.Results = []SearchResult{}
+		}
+		json.NewEncoder(w).Encode(sr)
+	}))
+}
+
+func TestClient_SearchAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		maxLimit  int
+		opts      SearchOptions
+		wantCount int
+		wantFirst string
+		wantCalls int32
+	}{
+		{name: "exhausts results", total: 25, opts: SearchOptions{PageSize: 10}, wantCount: 25, wantFirst: "slug-0", wantCalls: 4},
+		{name: "exact multiple of page size", total: 20, opts: SearchOptions{PageSize: 10}, wantCount: 20, wantFirst: "slug-0", wantCalls: 3},
+		{name: "stops at max", total: 100, opts: SearchOptions{PageSize: 10, Max: 15}, wantCount: 15, wantFirst: "slug-0", wantCalls: 2},
+		{name: "starts at offset", total: 25, opts: SearchOptions{PageSize: 10, Offset: 20}, wantCount: 5, wantFirst: "slug-20", wantCalls: 2},
+		{name: "server caps page size", total: 25, maxLimit: 7, opts: SearchOptions{PageSize: 10}, wantCount: 25, wantFirst: "slug-0", wantCalls: 5},
+		{name: "no results", total: 0, opts: SearchOptions{PageSize: 10}, wantCount: 0, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := newPagedSearchServer(t, tt.total, tt.maxLimit, &calls)
+			defer server.Close()
+
+			client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+
+			var got []SearchResult
+			for r, err := range client.SearchAll(context.Background(), "q", tt.opts) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				got = append(got, r)
+			}
+
+			if len(got) != tt.wantCount {
+				t.Errorf("got %d results, want %d", len(got), tt.wantCount)
+			}
+			if tt.wantFirst != "" && len(got) > 0 && got[0].Slug != tt.wantFirst {
+				t.Errorf("first slug = %q, want %q", got[0].Slug, tt.wantFirst)
+			}
+			if c := calls.Load(); c != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", c, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_SearchAllEarlyBreak(t *testing.T) {
+	var calls atomic.Int32
+	server := newPagedSearchServer(t, 100, 0, &calls)
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+
+	n := 0
+	for _, err := range client.SearchAll(context.Background(), "q", SearchOptions{PageSize: 10}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if c := calls.Load(); c != 1 {
+		t.Errorf("server called %d times, want 1", c)
+	}
+}
+
+func TestClient_SearchAllError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+
+	var errs int
+	for _, err := range client.SearchAll(context.Background(), "q", SearchOptions{}) {
+		if err == nil {
+			t.Fatal("expected error, got result")
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("got %d errors, want 1", errs)
+	}
+}
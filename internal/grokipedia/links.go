@@ -0,0 +1,205 @@
+package grokipedia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"grokir/internal/markdown"
+)
+
+// pagePathPrefix is the URL path under which Grokipedia serves articles.
+const pagePathPrefix = "/page/"
+
+// Citation is a source cited by a page.
+type Citation struct {
+	ID          string `json:"id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+// Link is a link found in page content.
+type Link struct {
+	// Text is the link text with markdown formatting removed.
+	Text string `json:"text"`
+	// URL is the link destination as written in the content.
+	URL string `json:"url"`
+	// Slug is the target page of an internal link.
+	Slug string `json:"slug,omitempty"`
+	// Broken is set by ValidateLinks when the target page does not
+	// exist.
+	Broken bool `json:"broken,omitempty"`
+}
+
+// PageLinks are the links and sources of a page.
+type PageLinks struct {
+	Slug string `json:"slug"`
+	// Internal links point to other Grokipedia pages.
+	Internal []Link `json:"internal"`
+	// External links point anywhere else.
+	External []Link `json:"external"`
+	// Citations are the references listed by the API.
+	Citations []Citation `json:"citations"`
+}
+
+// Links extracts the links of the page. Internal links are those to
+// pages of the Grokipedia site at baseURL, as recognized by SlugFromURL.
+// Each target is listed once, with the text of its first occurrence;
+// links to sections of the same page are skipped.
+func (p *Page) Links(baseURL string) PageLinks {
+	pl := PageLinks{
+		Slug:      p.Slug,
+		Internal:  []Link{},
+		External:  []Link{},
+		Citations: p.Citations,
+	}
+	if pl.Citations == nil {
+		pl.Citations = []Citation{}
+	}
+
+	seen := make(map[string]bool)
+	eachLink(markdown.Parse(p.Content), func(l markdown.Inline) {
+		link := Link{Text: markdown.PlainText(l.Children), URL: l.URL}
+		if link.URL == "" || strings.HasPrefix(link.URL, "#") {
+			return
+		}
+		key := link.URL
+		if slug, ok := SlugFromURL(baseURL, link.URL); ok {
+			link.Slug = slug
+			key = pagePathPrefix + slug
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if link.Slug != "" {
+			pl.Internal = append(pl.Internal, link)
+		} else {
+			pl.External = append(pl.External, link)
+		}
+	})
+	return pl
+}
+
+// eachLink calls fn for every link in blocks, in document order.
+func eachLink(blocks []markdown.Block, fn func(markdown.Inline)) {
+	eachInlineText(blocks, func(s string) {
+		walkInlines(markdown.ParseInline(s), fn)
+	})
+}
+
+// eachInlineText calls fn with the raw inline text of every paragraph,
+// heading and table cell in blocks, in document order.
+func eachInlineText(blocks []markdown.Block, fn func(string)) {
+	for _, b := range blocks {
+		switch b.Kind {
+		case markdown.Paragraph, markdown.Heading:
+			fn(b.Text)
+		case markdown.Quote:
+			eachInlineText(b.Children, fn)
+		case markdown.List:
+			for _, item := range b.Items {
+				eachInlineText(item, fn)
+			}
+		case markdown.Table:
+			for _, cell := range b.Header {
+				fn(cell)
+			}
+			for _, row := range b.Rows {
+				for _, cell := range row {
+					fn(cell)
+				}
+			}
+		}
+	}
+}
+
+func walkInlines(inlines []markdown.Inline, fn func(markdown.Inline)) {
+	for _, in := range inlines {
+		if in.Kind == markdown.Link {
+			fn(in)
+			continue
+		}
+		walkInlines(in.Children, fn)
+	}
+}
+
+// SlugFromURL returns the page slug that rawURL links to, if it is a
+// link to an article of the Grokipedia site at baseURL: either a path
+// such as "/page/Go" or an absolute URL on the host of baseURL, with or
+// without a "www." prefix.
+func SlugFromURL(baseURL, rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	if u.Host != "" {
+		base, err := url.Parse(baseURL)
+		if err != nil || siteHost(u) != siteHost(base) {
+			return "", false
+		}
+	} else if u.Scheme != "" {
+		return "", false
+	}
+	escaped, ok := strings.CutPrefix(u.EscapedPath(), pagePathPrefix)
+	if !ok || escaped == "" || strings.Contains(escaped, "/") {
+		return "", false
+	}
+	slug, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", false
+	}
+	return slug, true
+}
+
+// siteHost returns the host of u, lowercased and without a "www."
+// prefix.
+func siteHost(u *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+}
+
+// PageURL returns the address of the article slug on the Grokipedia
+// site at baseURL.
+func PageURL(baseURL, slug string) string {
+	return strings.TrimRight(baseURL, "/") + pagePathPrefix + url.PathEscape(slug)
+}
+
+// ValidateLinks checks that the target of every internal link exists,
+// setting Broken on those that do not. Other failures, such as network
+// errors, are returned without marking links, so that a broken link
+// always means the page was reported missing.
+func (c *Client) ValidateLinks(ctx context.Context, links []Link, opts GetPagesOptions) error {
+	var slugs []string
+	for _, l := range links {
+		if l.Slug != "" {
+			slugs = append(slugs, l.Slug)
+		}
+	}
+	opts.IncludeContent = false
+
+	missing := make(map[string]bool)
+	var failed int
+	var firstErr error
+	for _, r := range c.GetPages(ctx, slugs, opts) {
+		switch {
+		case r.Err == nil:
+		case errors.Is(r.Err, ErrNotFound):
+			missing[r.Slug] = true
+		default:
+			failed++
+			if firstErr == nil {
+				firstErr = r.Err
+			}
+		}
+	}
+	for i := range links {
+		links[i].Broken = missing[links[i].Slug]
+	}
+	if firstErr != nil {
+		return fmt.Errorf("%d of %d links could not be checked: %w", failed, len(slugs), firstErr)
+	}
+	return nil
+}
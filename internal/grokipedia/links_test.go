@@ -0,0 +1,144 @@
+package grokipedia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPage_Links(t *testing.T) {
+	page := &Page{
+		Slug: "Go",
+		Content: "Go was designed at [Google](https://www.google.com) by [Rob *Pike*](/page/Rob_Pike).\n\n" +
+			"## See also\n\n" +
+			"- [Pike](https://grokipedia.com/page/Rob_Pike)\n" +
+			"- [C++](/page/C%2B%2B)\n" +
+			"- [History](#history)\n\n" +
+			"| Language | Site |\n|---|---|\n| [Rust](/page/Rust) | [rust-lang.org](https://www.rust-lang.org) |\n\n" +
+			"```\n[not a link](/page/Code)\n```\n",
+		Citations: []Citation{{ID: "1", Title: "The Go Programming Language", URL: "https://go.dev"}},
+	}
+
+	got := page.Links(DefaultBaseURL)
+
+	wantInternal := []Link{
+		{Text: "Rob Pike", URL: "/page/Rob_Pike", Slug: "Rob_Pike"},
+		{Text: "C++", URL: "/page/C%2B%2B", Slug: "C++"},
+		{Text: "Rust", URL: "/page/Rust", Slug: "Rust"},
+	}
+	wantExternal := []Link{
+		{Text: "Google", URL: "https://www.google.com"},
+		{Text: "rust-lang.org", URL: "https://www.rust-lang.org"},
+	}
+	if !reflect.DeepEqual(got.Internal, wantInternal) {
+		t.Errorf("Internal = %+v, want %+v", got.Internal, wantInternal)
+	}
+	if !reflect.DeepEqual(got.External, wantExternal) {
+		t.Errorf("External = %+v, want %+v", got.External, wantExternal)
+	}
+	if !reflect.DeepEqual(got.Citations, page.Citations) {
+		t.Errorf("Citations = %+v, want %+v", got.Citations, page.Citations)
+	}
+}
+
+func TestPage_LinksEmpty(t *testing.T) {
+	got := (&Page{Slug: "empty"}).Links(DefaultBaseURL)
+	if got.Internal == nil || got.External == nil || got.Citations == nil {
+		t.Errorf("Links() = %+v, want empty non-nil slices", got)
+	}
+}
+
+func TestSlugFromURL(t *testing.T) {
+	tests := []struct {
+		base string
+		url  string
+		slug string
+		ok   bool
+	}{
+		{DefaultBaseURL, "/page/Go", "Go", true},
+		{DefaultBaseURL, "https://grokipedia.com/page/Go_(language)", "Go_(language)", true},
+		{DefaultBaseURL, "http://www.grokipedia.com/page/Go#History", "Go", true},
+		{DefaultBaseURL, "/page/C%2B%2B", "C++", true},
+		{DefaultBaseURL, "https://example.com/page/Go", "", false},
+		{DefaultBaseURL, "/page/", "", false},
+		{DefaultBaseURL, "/page/a/b", "", false},
+		{DefaultBaseURL, "/wiki/Go", "", false},
+		{DefaultBaseURL, "mailto:someone@example.com", "", false},
+		{"http://localhost:8080", "http://localhost:8080/page/Go", "Go", true},
+		{"http://localhost:8080", "https://grokipedia.com/page/Go", "", false},
+		{"http://localhost:8080", "/page/Go", "Go", true},
+	}
+	for _, tt := range tests {
+		slug, ok := SlugFromURL(tt.base, tt.url)
+		if slug != tt.slug || ok != tt.ok {
+			t.Errorf("SlugFromURL(%q, %q) = %q, %v; want %q, %v", tt.base, tt.url, slug, ok, tt.slug, tt.ok)
+		}
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	tests := map[string]string{
+		"Go":            "https://grokipedia.com/page/Go",
+		"Go_(language)": "https://grokipedia.com/page/Go_%28language%29",
+		"C++":           "https://grokipedia.com/page/C++",
+		"AC/DC":         "https://grokipedia.com/page/AC%2FDC",
+		"Café":          "https://grokipedia.com/page/Caf%C3%A9",
+	}
+	for slug, want := range tests {
+		if got := PageURL("https://grokipedia.com/", slug); got != want {
+			t.Errorf("PageURL(%q) = %q, want %q", slug, got, want)
+		}
+		if got, ok := SlugFromURL(DefaultBaseURL, want); !ok || got != slug {
+			t.Errorf("SlugFromURL(PageURL(%q)) = %q, %v", slug, got, ok)
+		}
+	}
+}
+
+func TestClient_ValidateLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("includeContent"); got != "false" {
+			t.Errorf("includeContent = %q, want false", got)
+		}
+		switch slug := r.URL.Query().Get("slug"); slug {
+		case "missing":
+			json.NewEncoder(w).Encode(pageResponse{Found: false})
+		case "broken-server":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			json.NewEncoder(w).Encode(pageResponse{Found: true, Page: &Page{Slug: slug}})
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTP: server.Client()}
+
+	t.Run("marks missing pages", func(t *testing.T) {
+		links := []Link{
+			{URL: "/page/Go", Slug: "Go"},
+			{URL: "/page/missing", Slug: "missing"},
+			{URL: "https://example.com"},
+		}
+		if err := client.ValidateLinks(context.Background(), links, GetPagesOptions{}); err != nil {
+			t.Fatalf("ValidateLinks() error = %v", err)
+		}
+		if links[0].Broken || !links[1].Broken || links[2].Broken {
+			t.Errorf("links = %+v, want only missing broken", links)
+		}
+	})
+
+	t.Run("reports other failures", func(t *testing.T) {
+		links := []Link{{Slug: "broken-server"}, {Slug: "Go"}}
+		err := client.ValidateLinks(context.Background(), links, GetPagesOptions{})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("ValidateLinks() error = %v, want 400 APIError", err)
+		}
+		if links[0].Broken {
+			t.Error("link that could not be checked was marked broken")
+		}
+	})
+}
@@ -0,0 +1,259 @@
+package grokipedia
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page represents a Grokipedia page from /api/page.
+//
+// Fields the API returns beyond those modelled here are kept in Extra,
+// as are modelled fields whose value does not have the expected shape,
+// so that a change in the API never makes a page unreadable and
+// re-encoding a page never loses data.
+type Page struct {
+	Title       string `json:"title"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+	// Citations are the sources the page cites.
+	Citations []Citation `json:"citations,omitempty"`
+	// Images are the images shown on the page.
+	Images []Image `json:"images,omitempty"`
+	// Metadata describes the page revision.
+	Metadata *PageMetadata `json:"metadata,omitempty"`
+	// Stats holds view counts and quality figures.
+	Stats *PageStats `json:"stats,omitempty"`
+	// LinkedPages are related pages suggested by the API.
+	LinkedPages []PageRef `json:"linkedPages,omitempty"`
+	CreatedAt   Timestamp `json:"createdAt,omitzero"`
+	UpdatedAt   Timestamp `json:"updatedAt,omitzero"`
+
+	// Extra is a JSON object holding every other field of the page.
+	Extra json.RawMessage `json:"-"`
+}
+
+// Image is an image shown on a page.
+type Image struct {
+	ID       string `json:"id,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	URL      string `json:"url"`
+	Position int    `json:"position,omitempty"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+}
+
+// PageMetadata describes a page revision.
+type PageMetadata struct {
+	Categories     []string  `json:"categories,omitempty"`
+	LastModified   Timestamp `json:"lastModified,omitzero"`
+	ContentLength  int       `json:"contentLength,omitempty"`
+	Version        string    `json:"version,omitempty"`
+	LastEditor     string    `json:"lastEditor,omitempty"`
+	Language       string    `json:"language,omitempty"`
+	IsRedirect     bool      `json:"isRedirect,omitempty"`
+	RedirectTarget string    `json:"redirectTarget,omitempty"`
+	IsWithheld     bool      `json:"isWithheld,omitempty"`
+}
+
+// PageStats holds view counts and quality figures of a page.
+type PageStats struct {
+	TotalViews    int64     `json:"totalViews,omitempty"`
+	RecentViews   int64     `json:"recentViews,omitempty"`
+	DailyAvgViews float64   `json:"dailyAvgViews,omitempty"`
+	QualityScore  float64   `json:"qualityScore,omitempty"`
+	LastViewed    Timestamp `json:"lastViewed,omitzero"`
+}
+
+// PageRef is a reference to another page.
+type PageRef struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title,omitempty"`
+}
+
+// pageFields returns a decoder for each modelled field of p, keyed by
+// its JSON name.
+func (p *Page) pageFields() map[string]func([]byte) error {
+	return map[string]func([]byte) error{
+		"title":       decodeInto(&p.Title),
+		"slug":        decodeInto(&p.Slug),
+		"description": decodeInto(&p.Description),
+		"content":     decodeInto(&p.Content),
+		"citations":   decodeInto(&p.Citations),
+		"images":      decodeInto(&p.Images),
+		"metadata":    decodeInto(&p.Metadata),
+		"stats":       decodeInto(&p.Stats),
+		"linkedPages": decodeInto(&p.LinkedPages),
+		"createdAt":   decodeInto(&p.CreatedAt),
+		"updatedAt":   decodeInto(&p.UpdatedAt),
+	}
+}
+
+// decodeInto returns a function that decodes JSON into dst, leaving dst
+// untouched when decoding fails.
+func decodeInto[T any](dst *T) func([]byte) error {
+	return func(data []byte) error {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*dst = v
+		return nil
+	}
+}
+
+// UnmarshalJSON decodes a page object, keeping unknown fields and fields
+// of an unexpected shape in Extra.
+func (p *Page) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*p = Page{}
+	known := p.pageFields()
+	extra := make(map[string]json.RawMessage)
+	for name, value := range fields {
+		if decode, ok := known[name]; ok && decode(value) == nil {
+			continue
+		}
+		extra[name] = value
+	}
+	if len(extra) > 0 {
+		raw, err := json.Marshal(extra)
+		if err != nil {
+			return err
+		}
+		p.Extra = raw
+	}
+	return nil
+}
+
+// MarshalJSON encodes the modelled fields of the page followed by the
+// other fields in Extra, sorted by name. A modelled field that is set
+// takes precedence over an Extra field of the same name; one that is
+// unset, as when the API's value had an unexpected shape, is encoded
+// with the Extra value instead.
+func (p Page) MarshalJSON() ([]byte, error) {
+	type page Page // without methods
+	data, err := json.Marshal(page(p))
+	if err != nil || len(p.Extra) == 0 {
+		return data, err
+	}
+
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(p.Extra, &extra); err != nil {
+		return nil, err
+	}
+	var unset map[string]json.RawMessage
+	zero, err := json.Marshal(page{})
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(zero, &unset); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	write := func(name string, value []byte) error {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+		return nil
+	}
+
+	buf.WriteByte('{')
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if v, ok := extra[name]; ok && bytes.Equal(value, unset[name]) {
+			value = v
+		}
+		delete(extra, name)
+		if err := write(name, value); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(extra)) {
+		if err := write(name, extra[name]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Timestamp is a point in time reported by the API. It decodes RFC 3339
+// strings as well as Unix times in seconds or milliseconds, given as
+// numbers or numeric strings, and encodes as RFC 3339.
+type Timestamp struct {
+	time.Time
+}
+
+// MarshalJSON encodes the timestamp as an RFC 3339 string.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON decodes an RFC 3339 string or a Unix time.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*t = Timestamp{}
+		return nil
+	}
+	if unq, err := strconv.Unquote(s); err == nil {
+		s = unq
+	}
+	if s == "" {
+		*t = Timestamp{}
+		return nil
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		*t = Timestamp{unixTime(n)}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*t = Timestamp{parsed}
+	return nil
+}
+
+// unixTime converts a Unix time to a time.Time, treating values too
+// large to be seconds as milliseconds.
+func unixTime(n float64) time.Time {
+	const maxSeconds = 1e11 // year 5138
+	if n > maxSeconds {
+		return time.UnixMilli(int64(n)).UTC()
+	}
+	sec := int64(n)
+	return time.Unix(sec, int64((n-float64(sec))*1e9)).UTC()
+}
@@ -0,0 +1,172 @@
+package grokipedia
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fullPageJSON = `{
+  "title": "Go",
+  "slug": "Go",
+  "description": "A programming language.",
+  "content": "# Go",
+  "citations": [{"id": "1", "title": "Go site", "url": "https://go.dev"}],
+  "images": [{"id": "img1", "caption": "Gopher", "url": "https://example.com/gopher.png", "width": 640, "height": 480}],
+  "metadata": {"categories": ["Programming languages"], "lastModified": 1700000000, "language": "en"},
+  "stats": {"totalViews": 1234, "qualityScore": 0.9, "lastViewed": "2024-01-02T03:04:05Z"},
+  "linkedPages": [{"slug": "Rust", "title": "Rust"}],
+  "updatedAt": "1700000000000",
+  "fixedIssues": [{"id": 7}],
+  "zeta": true
+}`
+
+func TestPage_UnmarshalFull(t *testing.T) {
+	var p Page
+	if err := json.Unmarshal([]byte(fullPageJSON), &p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if p.Title != "Go" || p.Content != "# Go" {
+		t.Errorf("basic fields = %q, %q", p.Title, p.Content)
+	}
+	if len(p.Citations) != 1 || p.Citations[0].URL != "https://go.dev" {
+		t.Errorf("Citations = %+v", p.Citations)
+	}
+	if len(p.Images) != 1 || p.Images[0].Width != 640 {
+		t.Errorf("Images = %+v", p.Images)
+	}
+	wantModified := time.Unix(1700000000, 0).UTC()
+	if p.Metadata == nil || !p.Metadata.LastModified.Equal(wantModified) || p.Metadata.Language != "en" {
+		t.Errorf("Metadata = %+v", p.Metadata)
+	}
+	if p.Stats == nil || p.Stats.TotalViews != 1234 || p.Stats.LastViewed.Year() != 2024 {
+		t.Errorf("Stats = %+v", p.Stats)
+	}
+	if len(p.LinkedPages) != 1 || p.LinkedPages[0].Slug != "Rust" {
+		t.Errorf("LinkedPages = %+v", p.LinkedPages)
+	}
+	if !p.UpdatedAt.Equal(wantModified) {
+		t.Errorf("UpdatedAt = %v, want %v (from milliseconds)", p.UpdatedAt, wantModified)
+	}
+
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(p.Extra, &extra); err != nil {
+		t.Fatalf("Extra is not a JSON object: %v", err)
+	}
+	if len(extra) != 2 || extra["fixedIssues"] == nil || extra["zeta"] == nil {
+		t.Errorf("Extra = %s, want fixedIssues and zeta", p.Extra)
+	}
+}
+
+func TestPage_UnmarshalUnexpectedShape(t *testing.T) {
+	var p Page
+	data := `{"title": "Go", "images": "not a list", "createdAt": "yesterday"}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if p.Title != "Go" || p.Images != nil || !p.CreatedAt.IsZero() {
+		t.Errorf("page = %+v", p)
+	}
+	if got := string(p.Extra); got != `{"createdAt":"yesterday","images":"not a list"}` {
+		t.Errorf("Extra = %s", got)
+	}
+
+	out, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(out), `"images":"not a list"`) || !strings.Contains(string(out), `"createdAt":"yesterday"`) {
+		t.Errorf("Marshal() = %s, lost fields of unexpected shape", out)
+	}
+}
+
+func TestPage_MarshalRoundTrip(t *testing.T) {
+	var p Page
+	if err := json.Unmarshal([]byte(fullPageJSON), &p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	out, err := json.Marshal(&p)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	s := string(out)
+	if !strings.HasPrefix(s, `{"title":"Go","slug":"Go",`) {
+		t.Errorf("Marshal() = %s, want modelled fields first", s)
+	}
+	if !strings.HasSuffix(s, `"fixedIssues":[{"id":7}],"zeta":true}`) {
+		t.Errorf("Marshal() = %s, want extra fields last in name order", s)
+	}
+	if strings.Contains(s, `"createdAt"`) {
+		t.Errorf("Marshal() = %s, want unset timestamp omitted", s)
+	}
+
+	var again Page
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatalf("Unmarshal(Marshal()) error = %v", err)
+	}
+	out2, err := json.Marshal(again)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(out2) != s {
+		t.Errorf("round trip changed output:\n%s\n%s", s, out2)
+	}
+}
+
+func TestPage_MarshalModelledFieldsWin(t *testing.T) {
+	p := Page{Title: "New", Extra: json.RawMessage(`{"title":"Old","other":1}`)}
+	out, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(out), "Old") || !strings.Contains(string(out), `"other":1`) {
+		t.Errorf("Marshal() = %s", out)
+	}
+}
+
+func TestPage_MarshalUnexpectedShapeOfRequiredField(t *testing.T) {
+	var p Page
+	if err := json.Unmarshal([]byte(`{"title":{"en":"Go"},"slug":"Go"}`), &p); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	out, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"title":{"en":"Go"},"slug":"Go","description":"","content":""}`; string(out) != want {
+		t.Errorf("Marshal() = %s, want %s", out, want)
+	}
+}
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	want := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{`"2023-11-14T22:13:20Z"`, want},
+		{`1700000000`, want},
+		{`1700000000000`, want},
+		{`"1700000000"`, want},
+		{`null`, time.Time{}},
+		{`""`, time.Time{}},
+	}
+	for _, tt := range tests {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(tt.in), &ts); err != nil {
+			t.Errorf("Unmarshal(%s) error = %v", tt.in, err)
+			continue
+		}
+		if !ts.Equal(tt.want) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.in, ts.Time, tt.want)
+		}
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"soon"`), &ts); err == nil {
+		t.Error("Unmarshal(\"soon\") succeeded, want error")
+	}
+}
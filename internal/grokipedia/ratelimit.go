@@ -0,0 +1,111 @@
+package grokipedia
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default request rate used by NewClient.
+const (
+	DefaultRate  = 5.0
+	DefaultBurst = 5
+)
+
+// Clock is the source of time for a RateLimiter. Tests substitute a
+// fake clock to make limiter behavior deterministic.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RateLimiter is a token bucket allowing Rate requests per second on
+// average with bursts of up to Burst requests. It is safe for use by
+// multiple goroutines, which are served in the order they call Wait.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  Clock
+}
+
+// NewRateLimiter returns a limiter allowing rate requests per second
+// with the given burst. A burst below 1 is treated as 1. If clock is
+// nil, the system clock is used.
+func NewRateLimiter(rate float64, burst int, clock Clock) *RateLimiter {
+	if clock == nil {
+		clock = realClock{}
+	}
+	b := float64(max(burst, 1))
+	return &RateLimiter{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   clock.Now(),
+		clock:  clock,
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done. A nil limiter
+// or one with a non-positive rate never blocks.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	// Reserve a token even if it is not available yet, so that
+	// concurrent waiters queue up behind each other.
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-l.clock.After(wait):
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Transport returns a RoundTripper that waits on l before every request
+// sent through next. Placing it below a response cache means cache hits
+// do not consume tokens.
+func (l *RateLimiter) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &rateLimitedTransport{limiter: l, next: next}
+}
+
+type rateLimitedTransport struct {
+	limiter *RateLimiter
+	next    http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
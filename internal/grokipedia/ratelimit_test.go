@@ -0,0 +1,170 @@
+package grokipedia
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock advances time instantly whenever the limiter asks to wait,
+// recording each requested delay.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+	block bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	if !c.block {
+		c.now = c.now.Add(d)
+		ch <- c.now
+	}
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter(2, 3, clock)
+	ctx := context.Background()
+
+	for i := range 5 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait() #%d error = %v", i, err)
+		}
+	}
+
+	want := []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}
+	if len(clock.waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", clock.waits, want)
+	}
+	for i := range want {
+		if clock.waits[i] != want[i] {
+			t.Errorf("wait[%d] = %v, want %v", i, clock.waits[i], want[i])
+		}
+	}
+
+	// A long idle period refills the bucket only up to the burst size.
+	clock.advance(time.Hour)
+	clock.waits = nil
+	for range 3 {
+		l.Wait(ctx)
+	}
+	if len(clock.waits) != 0 {
+		t.Errorf("waits after refill = %v, want none", clock.waits)
+	}
+	l.Wait(ctx)
+	if len(clock.waits) != 1 || clock.waits[0] != 500*time.Millisecond {
+		t.Errorf("waits after burst = %v, want [500ms]", clock.waits)
+	}
+}
+
+func TestRateLimiter_QueuesConcurrentWaiters(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), block: true}
+	l := NewRateLimiter(10, 1, clock)
+
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Wait(ctx)
+		}()
+	}
+	for {
+		clock.mu.Lock()
+		n := len(clock.waits)
+		clock.mu.Unlock()
+		if n == 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	got := map[time.Duration]bool{}
+	for _, d := range clock.waits {
+		got[d] = true
+	}
+	for _, d := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond} {
+		if !got[d] {
+			t.Errorf("waits = %v, missing %v", clock.waits, d)
+		}
+	}
+
+	// Cancelled waiters return their reservations.
+	clock.waits = nil
+	clock.block = false
+	l.Wait(context.Background())
+	if len(clock.waits) != 1 || clock.waits[0] != 100*time.Millisecond {
+		t.Errorf("wait after cancellations = %v, want [100ms]", clock.waits)
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	var l *RateLimiter
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter Wait() error = %v", err)
+	}
+
+	clock := &fakeClock{}
+	l = NewRateLimiter(0, 1, clock)
+	for range 10 {
+		l.Wait(context.Background())
+	}
+	if len(clock.waits) != 0 {
+		t.Errorf("zero-rate limiter waited %v", clock.waits)
+	}
+}
+
+func TestRateLimiter_Transport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results":[]}`))
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), block: true}
+	limiter := NewRateLimiter(1, 1, clock)
+	client := &Client{
+		BaseURL: server.URL,
+		HTTP:    &http.Client{Transport: limiter.Transport(server.Client().Transport)},
+	}
+
+	if _, err := client.Search("q", 10, 0); err != nil {
+		t.Fatalf("first Search() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.SearchContext(ctx, "q", 10, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("throttled Search() error = %v, want context.DeadlineExceeded", err)
+	}
+	if len(clock.waits) != 1 || clock.waits[0] != time.Second {
+		t.Errorf("waits = %v, want [1s]", clock.waits)
+	}
+}
@@ -0,0 +1,200 @@
+package grokipedia
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		retryAfter  string
+		policy      RetryPolicy
+		wantCalls   int32
+		wantWaits   []time.Duration
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:      "recovers after transient errors",
+			statuses:  []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			policy:    RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			wantCalls: 3,
+		},
+		{
+			name:        "gives up after max attempts",
+			statuses:    []int{http.StatusInternalServerError},
+			policy:      RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			wantCalls:   2,
+			wantErr:     true,
+			errContains: "500",
+		},
+		{
+			name:        "does not retry client errors",
+			statuses:    []int{http.StatusBadRequest},
+			policy:      RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond},
+			wantCalls:   1,
+			wantErr:     true,
+			errContains: "400",
+		},
+		{
+			name:        "disabled by zero policy",
+			statuses:    []int{http.StatusServiceUnavailable},
+			wantCalls:   1,
+			wantErr:     true,
+			errContains: "503",
+		},
+		{
+			name:       "honors Retry-After",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "3",
+			policy:     RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Minute},
+			wantCalls:  2,
+			wantWaits:  []time.Duration{3 * time.Second},
+		},
+		{
+			name:       "caps Retry-After at MaxDelay",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusOK},
+			retryAfter: "120",
+			policy:     RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second},
+			wantCalls:  2,
+			wantWaits:  []time.Duration{5 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write([]byte(`{"results":[]}`))
+				}
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := &Client{
+				BaseURL:   server.URL,
+				UserAgent: "grokir-test-agent",
+				HTTP:      server.Client(),
+				Retry:     tt.policy,
+				sleep: func(ctx context.Context, d time.Duration) error {
+					waits = append(waits, d)
+					return nil
+				},
+			}
+
+			_, err := client.Search("kubernetes", 10, 0)
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", got, tt.wantCalls)
+			}
+			if tt.wantWaits != nil {
+				if len(waits) != len(tt.wantWaits) {
+					t.Fatalf("waits = %v, want %v", waits, tt.wantWaits)
+				}
+				for i := range waits {
+					if waits[i] != tt.wantWaits[i] {
+						t.Errorf("wait[%d] = %v, want %v", i, waits[i], tt.wantWaits[i])
+					}
+				}
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("error %q does not contain %q", err.Error(), tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_RetryTransportError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatalf("hijack: %v", err)
+			}
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"found":true,"page":{"title":"Kubernetes","slug":"kubernetes"}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		BaseURL: server.URL,
+		HTTP:    server.Client(),
+		Retry:   RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	}
+
+	page, err := client.GetPage("kubernetes", true, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Title != "Kubernetes" {
+		t.Errorf("title = %q, want %q", page.Title, "Kubernetes")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2", got)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 8; attempt++ {
+		full := min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+		for range 20 {
+			d := p.backoff(attempt)
+			if d < full/2 || d >= full {
+				t.Fatalf("backoff(%d) = %v, want in [%v, %v)", attempt, d, full/2, full)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package grokipedia
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"grokir/internal/markdown"
+)
+
+// Section is a heading of a page together with the content under it.
+type Section struct {
+	// Title is the heading text with markdown formatting removed.
+	Title string `json:"title"`
+	// Level is the heading level, from 1 for "#" to 6 for "######".
+	Level int `json:"level"`
+	// Anchor identifies the section within the page. Anchors are
+	// unique per page and follow the usual markdown convention:
+	// lower-case words joined by hyphens.
+	Anchor string `json:"anchor"`
+	// Children are the sections nested directly under this one.
+	Children []Section `json:"children,omitempty"`
+
+	// markdown is the section source, from its heading up to the next
+	// heading of the same or a higher level.
+	markdown string
+}
+
+// Markdown returns the section source, starting with its heading and
+// including every nested section.
+func (s *Section) Markdown() string {
+	return s.markdown
+}
+
+// Sections parses the page content into a section tree.
+func (p *Page) Sections() []Section {
+	return ParseSections(p.Content)
+}
+
+// ParseSections parses markdown content into a section tree. Headings
+// inside fenced code blocks are ignored, as is any text before the first
+// heading. A heading that skips levels is nested under the nearest
+// heading above it with a lower level.
+func ParseSections(content string) []Section {
+	type heading struct {
+		title  string
+		level  int
+		anchor string
+		start  int
+	}
+
+	var heads []heading
+	anchors := make(map[string]bool)
+	fence := ""
+	for start := 0; start < len(content); {
+		end := strings.IndexByte(content[start:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += start
+		}
+		trimmed := strings.TrimSpace(content[start:end])
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			if level := headingLevel(trimmed); level > 0 {
+				text := strings.TrimSpace(trimmed[level:])
+				text = strings.TrimSpace(strings.TrimRight(text, "#"))
+				title := markdown.PlainText(markdown.ParseInline(text))
+				heads = append(heads, heading{
+					title:  title,
+					level:  level,
+					anchor: uniqueAnchor(anchors, title),
+					start:  start,
+				})
+			}
+		}
+		start = end + 1
+	}
+
+	// build returns the sections for heads[i:] up to the first heading
+	// at or above level, and the index of that heading.
+	var build func(i, level int) ([]Section, int)
+	build = func(i, level int) ([]Section, int) {
+		var out []Section
+		for i < len(heads) && heads[i].level > level {
+			h := heads[i]
+			children, next := build(i+1, h.level)
+			end := len(content)
+			if next < len(heads) {
+				end = heads[next].start
+			}
+			out = append(out, Section{
+				Title:    h.title,
+				Level:    h.level,
+				Anchor:   h.anchor,
+				Children: children,
+				markdown: strings.TrimRight(content[h.start:end], "\n"),
+			})
+			i = next
+		}
+		return out, i
+	}
+	sections, _ := build(0, 0)
+	return sections
+}
+
+// headingLevel returns the level of an ATX heading line, or zero when
+// trimmed is not a heading.
+func headingLevel(trimmed string) int {
+	n := 0
+	for n < len(trimmed) && trimmed[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 || (n < len(trimmed) && trimmed[n] != ' ') {
+		return 0
+	}
+	return n
+}
+
+// Anchor returns the anchor for a heading: lower-case letters and digits,
+// with words joined by hyphens.
+func Anchor(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// uniqueAnchor returns the anchor for title, suffixed with a counter when
+// an earlier heading already uses it.
+func uniqueAnchor(seen map[string]bool, title string) string {
+	base := Anchor(title)
+	if base == "" {
+		base = "section"
+	}
+	anchor := base
+	for n := 1; seen[anchor]; n++ {
+		anchor = base + "-" + strconv.Itoa(n)
+	}
+	seen[anchor] = true
+	return anchor
+}
+
+// FindSection returns the section of the tree that best matches query.
+// Matching ignores case and punctuation and tries, in order: the whole
+// heading or anchor, a heading starting with query, a heading containing
+// query, a heading containing every word of query, and finally a heading
+// within a few typos of query. Within each tier the first section in
+// document order wins. It reports false when no section matches.
+func FindSection(sections []Section, query string) (*Section, bool) {
+	q := matchKey(query)
+	if q == "" {
+		return nil, false
+	}
+	words := strings.Fields(q)
+
+	tiers := []func(s *Section) bool{
+		func(s *Section) bool {
+			return matchKey(s.Title) == q || s.Anchor == strings.ToLower(strings.TrimSpace(query))
+		},
+		func(s *Section) bool { return strings.HasPrefix(matchKey(s.Title), q) },
+		func(s *Section) bool { return strings.Contains(matchKey(s.Title), q) },
+		func(s *Section) bool {
+			title := matchKey(s.Title)
+			for _, w := range words {
+				if !strings.Contains(title, w) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+	for _, match := range tiers {
+		if s := findFirst(sections, match); s != nil {
+			return s, true
+		}
+	}
+
+	var best *Section
+	bestDist := len([]rune(q))/4 + 2
+	walkSections(sections, func(s *Section) {
+		if d := editDistance(matchKey(s.Title), q); d < bestDist {
+			best, bestDist = s, d
+		}
+	})
+	return best, best != nil
+}
+
+// walkSections calls fn for every section of the tree, depth first.
+func walkSections(sections []Section, fn func(*Section)) {
+	for i := range sections {
+		fn(&sections[i])
+		walkSections(sections[i].Children, fn)
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b, in
+// runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// findFirst returns the first section, depth first, for which match
+// reports true.
+func findFirst(sections []Section, match func(*Section) bool) *Section {
+	for i := range sections {
+		s := &sections[i]
+		if match(s) {
+			return s
+		}
+		if found := findFirst(s.Children, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// matchKey lower-cases s and reduces it to words separated by single
+// spaces, dropping punctuation.
+func matchKey(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
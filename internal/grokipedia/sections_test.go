@@ -0,0 +1,122 @@
+package grokipedia
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sectionsContent = `# Go
+
+Go is a programming language.
+
+## History
+
+Designed at Google.
+
+### Early *design*
+
+Started in 2007.
+
+## Design
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+#### Skipped levels
+
+## History
+
+Again.
+`
+
+// outline flattens a section tree into "level anchor title" lines.
+func outline(sections []Section) []string {
+	var out []string
+	walkSections(sections, func(s *Section) {
+		out = append(out, string(rune('0'+s.Level))+" "+s.Anchor+" "+s.Title)
+	})
+	return out
+}
+
+func TestParseSections(t *testing.T) {
+	sections := ParseSections(sectionsContent)
+
+	want := []string{
+		"1 go Go",
+		"2 history History",
+		"3 early-design Early design",
+		"2 design Design",
+		"4 skipped-levels Skipped levels",
+		"2 history-1 History",
+	}
+	if got := outline(sections); !reflect.DeepEqual(got, want) {
+		t.Fatalf("outline = %q, want %q", got, want)
+	}
+
+	if len(sections) != 1 || len(sections[0].Children) != 3 {
+		t.Fatalf("tree shape = %+v", sections)
+	}
+	history := sections[0].Children[0]
+	wantMD := "## History\n\nDesigned at Google.\n\n### Early *design*\n\nStarted in 2007."
+	if got := history.Markdown(); got != wantMD {
+		t.Errorf("Markdown() = %q, want %q", got, wantMD)
+	}
+	if got := sections[0].Children[1].Children[0].Level; got != 4 {
+		t.Errorf("skipped level = %d, want 4", got)
+	}
+}
+
+func TestParseSections_NoHeadings(t *testing.T) {
+	if got := ParseSections("Just a paragraph.\n"); got != nil {
+		t.Errorf("ParseSections() = %+v, want nil", got)
+	}
+}
+
+func TestFindSection(t *testing.T) {
+	sections := ParseSections(sectionsContent)
+
+	tests := []struct {
+		query  string
+		anchor string
+	}{
+		{"History", "history"},
+		{"history-1", "history-1"},
+		{"early", "early-design"},
+		{"DESIGN", "design"},
+		{"levels", "skipped-levels"},
+		{"levels skipped", "skipped-levels"},
+		{"Histroy", "history"},
+		{"zzz", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			s, ok := FindSection(sections, tt.query)
+			if tt.anchor == "" {
+				if ok {
+					t.Errorf("FindSection(%q) = %q, want no match", tt.query, s.Anchor)
+				}
+				return
+			}
+			if !ok || s.Anchor != tt.anchor {
+				t.Errorf("FindSection(%q) = %v, %v; want %q", tt.query, s, ok, tt.anchor)
+			}
+		})
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	tests := map[string]string{
+		"History":                "history",
+		"Early life and career":  "early-life-and-career",
+		"C++ (language)":         "c-language",
+		"Économie":               "économie",
+		"snake_case and-hyphens": "snake_case-and-hyphens",
+	}
+	for title, want := range tests {
+		if got := Anchor(title); got != want {
+			t.Errorf("Anchor(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
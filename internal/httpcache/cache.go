@@ -0,0 +1,219 @@
+// Package httpcache implements a small persistent cache for Grokipedia
+// API responses, plugged into an http.Client as a RoundTripper.
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is how long a stored response is served without contacting
+// the server.
+const DefaultTTL = 24 * time.Hour
+
+const entrySuffix = ".json"
+
+// Cache stores HTTP responses as files in a directory.
+type Cache struct {
+	// Dir is the directory holding cache entries.
+	Dir string
+	// TTL is how long an entry is considered fresh. Stale entries are
+	// revalidated with a conditional request when possible.
+	TTL time.Duration
+
+	// now returns the current time; tests replace it.
+	now func() time.Time
+}
+
+// New returns a cache rooted at dir.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// DefaultDir returns the default cache location, $XDG_CACHE_HOME/grokir
+// (or the platform equivalent).
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating cache directory: %w", err)
+	}
+	return filepath.Join(base, "grokir"), nil
+}
+
+// entry is the on-disk representation of a cached response.
+type entry struct {
+	URL          string    `json:"url"`
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"content_type,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	Body         []byte    `json:"body"`
+}
+
+// Stats summarizes the cache contents.
+type Stats struct {
+	Dir     string `json:"dir"`
+	Entries int    `json:"entries"`
+	Expired int    `json:"expired"`
+	Bytes   int64  `json:"bytes"`
+}
+
+func (c *Cache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *Cache) fresh(e *entry) bool {
+	return c.clock().Sub(e.StoredAt) < c.TTL
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+entrySuffix)
+}
+
+// load returns the entry stored for key, or nil if there is none or it
+// cannot be read.
+func (c *Cache) load(key string) *entry {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.URL != key {
+		return nil
+	}
+	return &e
+}
+
+// store writes e atomically so concurrent readers never see a partial
+// entry.
+func (c *Cache) store(e *entry) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(e.URL)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return nil
+}
+
+// walk calls fn for every entry file in the cache directory. A missing
+// directory is treated as an empty cache.
+func (c *Cache) walk(fn func(path string, info fs.FileInfo, e *entry) error) error {
+	dirEntries, err := os.ReadDir(c.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading cache directory: %w", err)
+	}
+	for _, de := range dirEntries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), entrySuffix) {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.Dir, de.Name())
+		var e *entry
+		if data, err := os.ReadFile(path); err == nil {
+			var parsed entry
+			if json.Unmarshal(data, &parsed) == nil {
+				e = &parsed
+			}
+		}
+		if err := fn(path, info, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats reports the number and total size of cached entries.
+func (c *Cache) Stats() (Stats, error) {
+	st := Stats{Dir: c.Dir}
+	err := c.walk(func(path string, info fs.FileInfo, e *entry) error {
+		st.Entries++
+		st.Bytes += info.Size()
+		if e == nil || !c.fresh(e) {
+			st.Expired++
+		}
+		return nil
+	})
+	return st, err
+}
+
+// Clear removes every cached entry and returns how many were removed.
+func (c *Cache) Clear() (int, error) {
+	return c.remove(func(*entry) bool { return true })
+}
+
+// Prune removes expired and unreadable entries and returns how many were
+// removed.
+func (c *Cache) Prune() (int, error) {
+	return c.remove(func(e *entry) bool { return e == nil || !c.fresh(e) })
+}
+
+func (c *Cache) remove(match func(*entry) bool) (int, error) {
+	removed := 0
+	err := c.walk(func(path string, _ fs.FileInfo, e *entry) error {
+		if !match(e) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing cache entry: %w", err)
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+// Each calls fn with the request URL and body of every readable entry,
+// stopping at the first error fn returns.
+func (c *Cache) Each(fn func(url string, body []byte) error) error {
+	return c.walk(func(_ string, _ fs.FileInfo, e *entry) error {
+		if e == nil {
+			return nil
+		}
+		return fn(e.URL, e.Body)
+	})
+}
+
+// Transport returns a RoundTripper that serves GET requests from the
+// cache and forwards everything else to next.
+func (c *Cache) Transport(next http.RoundTripper) *Transport {
+	return &Transport{Cache: c, Next: next}
+}
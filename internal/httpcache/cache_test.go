@@ -0,0 +1,66 @@
+package httpcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_StatsPruneClear(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := New(filepath.Join(t.TempDir(), "grokir"), time.Hour)
+	cache.now = func() time.Time { return now }
+
+	st, err := cache.Stats()
+	if err != nil {
+		t.Fatalf("Stats() on missing dir error = %v", err)
+	}
+	if st.Entries != 0 {
+		t.Fatalf("Entries = %d, want 0", st.Entries)
+	}
+
+	entries := []*entry{
+		{URL: "https://example.test/a", StatusCode: 200, StoredAt: now, Body: []byte("a")},
+		{URL: "https://example.test/b", StatusCode: 200, StoredAt: now.Add(-2 * time.Hour), Body: []byte("b")},
+		{URL: "https://example.test/c", StatusCode: 200, StoredAt: now.Add(-3 * time.Hour), Body: []byte("c")},
+	}
+	for _, e := range entries {
+		if err := cache.store(e); err != nil {
+			t.Fatalf("store() error = %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(cache.Dir, "corrupt.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err = cache.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if st.Entries != 4 || st.Expired != 3 || st.Bytes == 0 {
+		t.Errorf("Stats() = %+v, want 4 entries, 3 expired, non-zero bytes", st)
+	}
+
+	removed, err := cache.Prune()
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("Prune() removed %d, want 3", removed)
+	}
+	if cache.load("https://example.test/a") == nil {
+		t.Error("fresh entry was pruned")
+	}
+
+	removed, err = cache.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Clear() removed %d, want 1", removed)
+	}
+	if st, _ := cache.Stats(); st.Entries != 0 {
+		t.Errorf("Entries after Clear() = %d, want 0", st.Entries)
+	}
+}
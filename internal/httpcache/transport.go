@@ -0,0 +1,142 @@
+package httpcache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// StatusHeader is set on responses produced by Transport to "hit" when
+// served from the cache without contacting the server, "revalidated"
+// when the server confirmed the stored copy, "offline" when served in
+// offline mode, and "miss" otherwise.
+const StatusHeader = "X-Grokir-Cache"
+
+// ErrNotCached is returned in offline mode for requests that have no
+// stored response.
+var ErrNotCached = errors.New("not available offline")
+
+// Transport is an http.RoundTripper backed by a Cache. Fresh entries are
+// served directly; stale entries carrying an ETag or Last-Modified value
+// are revalidated with a conditional request.
+type Transport struct {
+	Cache *Cache
+	// Next performs requests that cannot be served from the cache. If
+	// nil, http.DefaultTransport is used.
+	Next http.RoundTripper
+	// Offline serves every GET request from the cache regardless of
+	// age and never contacts the server; misses fail with ErrNotCached.
+	Offline bool
+	// Storable reports whether a 200 response to req with the given
+	// body may be stored, for APIs that report failures such as missing
+	// resources in successful responses. If nil, every one is stored.
+	Storable func(req *http.Request, body []byte) bool
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next().RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	cached := t.Cache.load(key)
+	if t.Offline {
+		if cached == nil {
+			return nil, fmt.Errorf("%s: %w", key, ErrNotCached)
+		}
+		return cached.response(req, "offline"), nil
+	}
+	if cached != nil && t.Cache.fresh(cached) {
+		return cached.response(req, "hit"), nil
+	}
+
+	outReq := req
+	if cached != nil && (cached.ETag != "" || cached.LastModified != "") {
+		outReq = req.Clone(req.Context())
+		if cached.ETag != "" {
+			outReq.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			outReq.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.next().RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		cached.StoredAt = t.Cache.clock()
+		// A failed refresh only means the entry is revalidated again
+		// next time.
+		_ = t.Cache.store(cached)
+		return cached.response(req, "revalidated"), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.Header.Set(StatusHeader, "miss")
+	if t.Storable != nil && !t.Storable(req, body) {
+		return resp, nil
+	}
+
+	// The response is still usable when it cannot be stored.
+	_ = t.Cache.store(&entry{
+		URL:          key,
+		StatusCode:   resp.StatusCode,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		StoredAt:     t.Cache.clock(),
+		Body:         body,
+	})
+	return resp, nil
+}
+
+// response rebuilds an *http.Response for req from the entry.
+func (e *entry) response(req *http.Request, status string) *http.Response {
+	h := make(http.Header)
+	if e.ContentType != "" {
+		h.Set("Content-Type", e.ContentType)
+	}
+	if e.ETag != "" {
+		h.Set("ETag", e.ETag)
+	}
+	if e.LastModified != "" {
+		h.Set("Last-Modified", e.LastModified)
+	}
+	h.Set("Content-Length", strconv.Itoa(len(e.Body)))
+	h.Set(StatusHeader, status)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,238 @@
+package httpcache
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		etag         string
+		lastModified string
+		age          time.Duration
+		wantCalls    int32
+		wantStatus   string
+		wantBody     string
+		wantCond     bool
+	}{
+		{
+			name:       "fresh entry served from cache",
+			etag:       `"v1"`,
+			age:        time.Minute,
+			wantCalls:  1,
+			wantStatus: "hit",
+			wantBody:   "body-1",
+		},
+		{
+			name:       "stale entry revalidated by ETag",
+			etag:       `"v1"`,
+			age:        2 * time.Hour,
+			wantCalls:  2,
+			wantStatus: "revalidated",
+			wantBody:   "body-1",
+			wantCond:   true,
+		},
+		{
+			name:         "stale entry revalidated by Last-Modified",
+			lastModified: "Mon, 02 Jan 2006 15:04:05 GMT",
+			age:          2 * time.Hour,
+			wantCalls:    2,
+			wantStatus:   "revalidated",
+			wantBody:     "body-1",
+			wantCond:     true,
+		},
+		{
+			name:       "stale entry without validators refetched",
+			age:        2 * time.Hour,
+			wantCalls:  2,
+			wantStatus: "miss",
+			wantBody:   "body-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var sawCond atomic.Bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+					if r.Header.Get("If-None-Match") == tt.etag {
+						sawCond.Store(true)
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+				}
+				if tt.lastModified != "" {
+					w.Header().Set("Last-Modified", tt.lastModified)
+					if r.Header.Get("If-Modified-Since") == tt.lastModified {
+						sawCond.Store(true)
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+				}
+				io.WriteString(w, "body-"+string(rune('0'+n)))
+			}))
+			defer server.Close()
+
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			cache := New(t.TempDir(), time.Hour)
+			cache.now = func() time.Time { return now }
+			client := &http.Client{Transport: cache.Transport(server.Client().Transport)}
+
+			get := func() (string, string) {
+				resp, err := client.Get(server.URL + "/api/page?slug=kubernetes")
+				if err != nil {
+					t.Fatalf("GET error: %v", err)
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				return string(body), resp.Header.Get(StatusHeader)
+			}
+
+			if body, status := get(); body != "body-1" || status != "miss" {
+				t.Fatalf("first GET = %q (%s), want %q (miss)", body, status, "body-1")
+			}
+
+			now = now.Add(tt.age)
+			body, status := get()
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", got, tt.wantCalls)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("cache status = %q, want %q", status, tt.wantStatus)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if sawCond.Load() != tt.wantCond {
+				t.Errorf("conditional request = %t, want %t", sawCond.Load(), tt.wantCond)
+			}
+		})
+	}
+}
+
+func TestTransport_DoesNotStoreErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cache := New(t.TempDir(), time.Hour)
+	client := &http.Client{Transport: cache.Transport(server.Client().Transport)}
+
+	for range 2 {
+		resp, err := client.Get(server.URL + "/api/full-text-search?query=x")
+		if err != nil {
+			t.Fatalf("GET error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 503", resp.StatusCode)
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2", got)
+	}
+
+	st, err := cache.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if st.Entries != 0 {
+		t.Errorf("Entries = %d, want 0", st.Entries)
+	}
+}
+
+func TestTransport_Storable(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"found":false}`))
+	}))
+	defer server.Close()
+
+	cache := New(t.TempDir(), time.Hour)
+	transport := cache.Transport(server.Client().Transport)
+	transport.Storable = func(req *http.Request, body []byte) bool {
+		return string(body) != `{"found":false}`
+	}
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(server.URL + "/api/page?slug=x")
+		if err != nil {
+			t.Fatalf("GET error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != `{"found":false}` {
+			t.Fatalf("body = %q, want the server response", body)
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server called %d times, want 2", got)
+	}
+
+	st, err := cache.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if st.Entries != 0 {
+		t.Errorf("Entries = %d, want 0", st.Entries)
+	}
+}
+
+func TestTransport_Offline(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		io.WriteString(w, "cached body")
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := New(t.TempDir(), time.Hour)
+	cache.now = func() time.Time { return now }
+
+	online := &http.Client{Transport: cache.Transport(server.Client().Transport)}
+	resp, err := online.Get(server.URL + "/api/page?slug=kubernetes")
+	if err != nil {
+		t.Fatalf("online GET error: %v", err)
+	}
+	resp.Body.Close()
+
+	now = now.Add(30 * 24 * time.Hour)
+	tr := cache.Transport(server.Client().Transport)
+	tr.Offline = true
+	offline := &http.Client{Transport: tr}
+
+	resp, err = offline.Get(server.URL + "/api/page?slug=kubernetes")
+	if err != nil {
+		t.Fatalf("offline GET of cached URL error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "cached body" || resp.Header.Get(StatusHeader) != "offline" {
+		t.Errorf("offline GET = %q (%s), want %q (offline)", body, resp.Header.Get(StatusHeader), "cached body")
+	}
+
+	_, err = offline.Get(server.URL + "/api/page?slug=missing")
+	if !errors.Is(err, ErrNotCached) {
+		t.Errorf("offline GET of uncached URL error = %v, want ErrNotCached", err)
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server called %d times, want 1", got)
+	}
+}
@@ -0,0 +1,422 @@
+// Package markdown parses the subset of Markdown used in Grokipedia
+// articles into a small block and inline tree that formatters render
+// for terminals, HTML and e-books.
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BlockKind identifies the type of a Block.
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	Heading
+	CodeBlock
+	Quote
+	List
+	Table
+	Rule
+)
+
+// Align is the alignment of a table column.
+type Align int
+
+const (
+	AlignNone Align = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// Block is a block-level element.
+type Block struct {
+	Kind BlockKind
+
+	// Level is the heading level, 1 to 6.
+	Level int
+	// Text is the raw inline text of a paragraph or heading, or the
+	// verbatim contents of a code block.
+	Text string
+	// Lang is the info string of a fenced code block.
+	Lang string
+
+	// Ordered and Start describe a list; Items holds the blocks of each
+	// list item. A list is Loose when its items are separated by blank
+	// lines.
+	Ordered bool
+	Start   int
+	Loose   bool
+	Items   [][]Block
+
+	// Children holds the blocks of a block quote.
+	Children []Block
+
+	// Header, Rows and Align describe a table. Cells hold raw inline
+	// text.
+	Header []string
+	Rows   [][]string
+	Align  []Align
+}
+
+// Parse splits src into blocks.
+func Parse(src string) []Block {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+	p := &parser{lines: strings.Split(src, "\n")}
+	return p.blocks()
+}
+
+type parser struct {
+	lines []string
+	pos   int
+}
+
+func (p *parser) blocks() []Block {
+	var out []Block
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			p.pos++
+		case fenceMarker(trimmed) != "":
+			out = append(out, p.fenced())
+		case headingLevel(trimmed) > 0:
+			level := headingLevel(trimmed)
+			text := strings.TrimSpace(trimmed[level:])
+			text = strings.TrimSpace(strings.TrimRight(text, "#"))
+			out = append(out, Block{Kind: Heading, Level: level, Text: text})
+			p.pos++
+		case isRule(trimmed):
+			out = append(out, Block{Kind: Rule})
+			p.pos++
+		case strings.HasPrefix(trimmed, ">"):
+			out = append(out, p.quote())
+		case listMarker(line) != nil:
+			out = append(out, p.list())
+		case p.isTableStart():
+			out = append(out, p.table())
+		default:
+			out = append(out, p.paragraph())
+		}
+	}
+	return out
+}
+
+// startsBlock reports whether line interrupts a paragraph.
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" ||
+		fenceMarker(trimmed) != "" ||
+		headingLevel(trimmed) > 0 ||
+		isRule(trimmed) ||
+		strings.HasPrefix(trimmed, ">") ||
+		listMarker(line) != nil
+}
+
+func (p *parser) paragraph() Block {
+	var parts []string
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if len(parts) > 0 && (startsBlock(line) || p.isTableStart()) {
+			break
+		}
+		parts = append(parts, strings.TrimSpace(line))
+		p.pos++
+	}
+	return Block{Kind: Paragraph, Text: strings.Join(parts, "\n")}
+}
+
+func fenceMarker(trimmed string) string {
+	for _, m := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, m) {
+			return m
+		}
+	}
+	return ""
+}
+
+func (p *parser) fenced() Block {
+	open := strings.TrimSpace(p.lines[p.pos])
+	marker := fenceMarker(open)
+	indent := len(p.lines[p.pos]) - len(strings.TrimLeft(p.lines[p.pos], " "))
+	lang := strings.TrimSpace(strings.TrimLeft(open, marker[:1]))
+	p.pos++
+
+	var code []string
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		p.pos++
+		if strings.HasPrefix(strings.TrimSpace(line), marker) && strings.Trim(strings.TrimSpace(line), marker[:1]) == "" {
+			break
+		}
+		code = append(code, dedent(line, indent))
+	}
+	return Block{Kind: CodeBlock, Lang: lang, Text: strings.Join(code, "\n")}
+}
+
+func headingLevel(trimmed string) int {
+	n := 0
+	for n < len(trimmed) && trimmed[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 {
+		return 0
+	}
+	if n < len(trimmed) && trimmed[n] != ' ' {
+		return 0
+	}
+	return n
+}
+
+func isRule(trimmed string) bool {
+	if len(trimmed) < 3 {
+		return false
+	}
+	c := trimmed[0]
+	if c != '-' && c != '*' && c != '_' {
+		return false
+	}
+	count := 0
+	for i := 0; i < len(trimmed); i++ {
+		switch trimmed[i] {
+		case c:
+			count++
+		case ' ':
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+func (p *parser) quote() Block {
+	var inner []string
+	for p.pos < len(p.lines) {
+		trimmed := strings.TrimSpace(p.lines[p.pos])
+		if !strings.HasPrefix(trimmed, ">") {
+			// Lazy continuation of a quoted paragraph.
+			if trimmed == "" || startsBlock(p.lines[p.pos]) || len(inner) == 0 || strings.TrimSpace(inner[len(inner)-1]) == "" {
+				break
+			}
+			inner = append(inner, trimmed)
+			p.pos++
+			continue
+		}
+		line := strings.TrimPrefix(trimmed, ">")
+		line = strings.TrimPrefix(line, " ")
+		inner = append(inner, line)
+		p.pos++
+	}
+	return Block{Kind: Quote, Children: Parse(strings.Join(inner, "\n"))}
+}
+
+// marker describes a list item marker found at the start of a line.
+type marker struct {
+	ordered bool
+	number  int
+	bullet  byte
+	// indent is the column where the item's content starts.
+	indent int
+}
+
+func listMarker(line string) *marker {
+	lead := len(line) - len(strings.TrimLeft(line, " "))
+	rest := line[lead:]
+	if rest == "" {
+		return nil
+	}
+	switch rest[0] {
+	case '-', '*', '+':
+		if len(rest) < 2 || rest[1] != ' ' || isRule(strings.TrimSpace(rest)) {
+			return nil
+		}
+		return &marker{bullet: rest[0], indent: lead + 2 + countSpaces(rest[2:])}
+	}
+	i := 0
+	for i < len(rest) && i < 9 && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	if i == 0 || i+1 >= len(rest) || (rest[i] != '.' && rest[i] != ')') || rest[i+1] != ' ' {
+		return nil
+	}
+	n, _ := strconv.Atoi(rest[:i])
+	return &marker{ordered: true, number: n, indent: lead + i + 2 + countSpaces(rest[i+2:])}
+}
+
+func countSpaces(s string) int {
+	n := 0
+	for n < len(s) && s[n] == ' ' && n < 3 {
+		n++
+	}
+	return n
+}
+
+func (p *parser) list() Block {
+	first := listMarker(p.lines[p.pos])
+	b := Block{Kind: List, Ordered: first.ordered, Start: first.number}
+	baseIndent := len(p.lines[p.pos]) - len(strings.TrimLeft(p.lines[p.pos], " "))
+
+	for p.pos < len(p.lines) {
+		m := listMarker(p.lines[p.pos])
+		lead := len(p.lines[p.pos]) - len(strings.TrimLeft(p.lines[p.pos], " "))
+		if m == nil || m.ordered != first.ordered || lead != baseIndent {
+			break
+		}
+
+		item := []string{p.lines[p.pos][m.indent:]}
+		p.pos++
+		for p.pos < len(p.lines) {
+			line := p.lines[p.pos]
+			trimmed := strings.TrimSpace(line)
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			if trimmed == "" {
+				// A blank line continues the item only if the next
+				// non-blank line is indented into it.
+				next := p.pos + 1
+				for next < len(p.lines) && strings.TrimSpace(p.lines[next]) == "" {
+					next++
+				}
+				if next < len(p.lines) {
+					nl := p.lines[next]
+					if len(nl)-len(strings.TrimLeft(nl, " ")) >= m.indent {
+						b.Loose = true
+						item = append(item, "")
+						p.pos++
+						continue
+					}
+				}
+				break
+			}
+			if indent >= m.indent {
+				item = append(item, dedent(line, m.indent))
+				p.pos++
+				continue
+			}
+			if indent <= baseIndent && listMarker(line) != nil || startsBlock(line) && listMarker(line) == nil {
+				break
+			}
+			if listMarker(line) != nil {
+				// A nested list indented less than the content column.
+				item = append(item, dedent(line, baseIndent+1))
+				p.pos++
+				continue
+			}
+			// Lazy paragraph continuation.
+			item = append(item, trimmed)
+			p.pos++
+		}
+		b.Items = append(b.Items, Parse(strings.Join(item, "\n")))
+
+		// Skip blank lines between items of the same list.
+		save := p.pos
+		for p.pos < len(p.lines) && strings.TrimSpace(p.lines[p.pos]) == "" {
+			p.pos++
+		}
+		if p.pos >= len(p.lines) || listMarker(p.lines[p.pos]) == nil {
+			p.pos = save
+			break
+		}
+		if p.pos > save {
+			b.Loose = true
+		}
+	}
+	return b
+}
+
+func dedent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
+
+func (p *parser) isTableStart() bool {
+	if p.pos+1 >= len(p.lines) {
+		return false
+	}
+	header := strings.TrimSpace(p.lines[p.pos])
+	if !strings.Contains(header, "|") {
+		return false
+	}
+	delim := splitRow(p.lines[p.pos+1])
+	if len(delim) == 0 || len(delim) != len(splitRow(header)) {
+		return false
+	}
+	for _, cell := range delim {
+		c := strings.Trim(cell, ":")
+		if c == "" || strings.Trim(c, "-") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) table() Block {
+	b := Block{Kind: Table, Header: splitRow(p.lines[p.pos])}
+	for _, cell := range splitRow(p.lines[p.pos+1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			b.Align = append(b.Align, AlignCenter)
+		case right:
+			b.Align = append(b.Align, AlignRight)
+		case left:
+			b.Align = append(b.Align, AlignLeft)
+		default:
+			b.Align = append(b.Align, AlignNone)
+		}
+	}
+	p.pos += 2
+
+	for p.pos < len(p.lines) {
+		line := strings.TrimSpace(p.lines[p.pos])
+		if line == "" || !strings.Contains(line, "|") {
+			break
+		}
+		row := splitRow(line)
+		for len(row) < len(b.Header) {
+			row = append(row, "")
+		}
+		b.Rows = append(b.Rows, row[:len(b.Header)])
+		p.pos++
+	}
+	return b
+}
+
+// splitRow splits a table row into trimmed cells, honoring escaped
+// pipes and pipes inside code spans.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cur strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cur.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			cur.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	cells = append(cells, strings.TrimSpace(cur.String()))
+	return cells
+}
@@ -0,0 +1,285 @@
+package markdown
+
+import "strings"
+
+// InlineKind identifies the type of an Inline.
+type InlineKind int
+
+const (
+	Text InlineKind = iota
+	Emphasis
+	Strong
+	Code
+	Link
+	Image
+	LineBreak
+)
+
+// Inline is a span of inline content.
+type Inline struct {
+	Kind InlineKind
+	// Text is the literal text of a Text or Code span, or the alt text
+	// of an Image.
+	Text string
+	// URL is the destination of a Link or the source of an Image.
+	URL string
+	// Children holds the content of Emphasis, Strong and Link spans.
+	Children []Inline
+}
+
+// ParseInline parses emphasis, code spans, links and images in s.
+// Newlines become spaces, except for hard breaks (two trailing spaces
+// or a trailing backslash).
+func ParseInline(s string) []Inline {
+	ip := &inlineParser{src: s}
+	return ip.parse(0, len(s))
+}
+
+// PlainText returns the text content of inlines with all markup
+// removed.
+func PlainText(inlines []Inline) string {
+	var b strings.Builder
+	for _, in := range inlines {
+		switch in.Kind {
+		case Text, Code, Image:
+			b.WriteString(in.Text)
+		case LineBreak:
+			b.WriteString(" ")
+		default:
+			b.WriteString(PlainText(in.Children))
+		}
+	}
+	return b.String()
+}
+
+type inlineParser struct {
+	src string
+}
+
+func (ip *inlineParser) parse(start, end int) []Inline {
+	var out []Inline
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, Inline{Kind: Text, Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	s := ip.src
+	for i := start; i < end; {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < end && s[i+1] == '\n':
+			flush()
+			out = append(out, Inline{Kind: LineBreak})
+			i += 2
+			continue
+		case c == '\\' && i+1 < end && isPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '\n':
+			str := text.String()
+			if strings.HasSuffix(str, "  ") {
+				text.Reset()
+				text.WriteString(strings.TrimRight(str, " "))
+				flush()
+				out = append(out, Inline{Kind: LineBreak})
+			} else {
+				text.Reset()
+				text.WriteString(strings.TrimRight(str, " "))
+				text.WriteByte(' ')
+			}
+			i++
+			for i < end && s[i] == ' ' {
+				i++
+			}
+			continue
+		case c == '`':
+			n := runLength(s, i, end, '`')
+			if close := strings.Index(s[i+n:end], strings.Repeat("`", n)); close >= 0 {
+				flush()
+				code := s[i+n : i+n+close]
+				code = strings.ReplaceAll(code, "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				out = append(out, Inline{Kind: Code, Text: code})
+				i += n + close + n
+				continue
+			}
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		case c == '!' && i+1 < end && s[i+1] == '[':
+			if label, url, next, ok := ip.link(i+1, end); ok {
+				flush()
+				out = append(out, Inline{Kind: Image, Text: PlainText(ip.parse(label[0], label[1])), URL: url})
+				i = next
+				continue
+			}
+		case c == '[':
+			if label, url, next, ok := ip.link(i, end); ok {
+				flush()
+				out = append(out, Inline{Kind: Link, URL: url, Children: ip.parse(label[0], label[1])})
+				i = next
+				continue
+			}
+		case c == '<':
+			if close := strings.IndexByte(s[i:end], '>'); close > 0 {
+				url := s[i+1 : i+close]
+				if isAutolink(url) {
+					flush()
+					out = append(out, Inline{Kind: Link, URL: url, Children: []Inline{{Kind: Text, Text: url}}})
+					i += close + 1
+					continue
+				}
+			}
+		case c == '*' || c == '_':
+			if span, next, ok := ip.emphasis(i, end); ok {
+				flush()
+				out = append(out, span)
+				i = next
+				continue
+			}
+			n := runLength(s, i, end, c)
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return out
+}
+
+// link parses [label](url) starting at the opening bracket. It returns
+// the label bounds, the destination and the index after the link.
+func (ip *inlineParser) link(i, end int) ([2]int, string, int, bool) {
+	s := ip.src
+	depth := 0
+	j := i
+	for ; j < end; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			if close := strings.IndexByte(s[j+1:end], '`'); close >= 0 {
+				j += close + 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if j >= end || j+1 >= end || s[j+1] != '(' {
+		return [2]int{}, "", 0, false
+	}
+
+	k := j + 2
+	parens := 1
+	for ; k < end; k++ {
+		if s[k] == '(' {
+			parens++
+		} else if s[k] == ')' {
+			parens--
+			if parens == 0 {
+				break
+			}
+		}
+	}
+	if k >= end {
+		return [2]int{}, "", 0, false
+	}
+
+	dest := strings.TrimSpace(s[j+2 : k])
+	// Drop an optional title: [text](url "title").
+	if sp := strings.IndexAny(dest, " \n"); sp >= 0 {
+		dest = dest[:sp]
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	return [2]int{i + 1, j}, dest, k + 1, true
+}
+
+// emphasis parses *em*, **strong** and their underscore forms starting
+// at i.
+func (ip *inlineParser) emphasis(i, end int) (Inline, int, bool) {
+	s := ip.src
+	c := s[i]
+	n := runLength(s, i, end, c)
+	if n > 3 {
+		return Inline{}, 0, false
+	}
+	openEnd := i + n
+	if openEnd >= end || s[openEnd] == ' ' || s[openEnd] == '\n' {
+		return Inline{}, 0, false
+	}
+	// Intraword underscores are literal, as in snake_case.
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return Inline{}, 0, false
+	}
+
+	delim := strings.Repeat(string(c), n)
+	for j := openEnd; j < end; j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+			continue
+		case s[j] == '`':
+			if close := strings.IndexByte(s[j+1:end], '`'); close >= 0 {
+				j += close + 1
+			}
+			continue
+		}
+		if !strings.HasPrefix(s[j:end], delim) || s[j-1] == ' ' || s[j-1] == '\n' {
+			continue
+		}
+		if j+n < end && s[j+n] == c {
+			// Part of a longer run; let the inner span close first.
+			continue
+		}
+		if c == '_' && j+n < end && isWordByte(s[j+n]) {
+			continue
+		}
+
+		inner := ip.parse(openEnd, j)
+		var span Inline
+		switch n {
+		case 1:
+			span = Inline{Kind: Emphasis, Children: inner}
+		case 2:
+			span = Inline{Kind: Strong, Children: inner}
+		default:
+			span = Inline{Kind: Strong, Children: []Inline{{Kind: Emphasis, Children: inner}}}
+		}
+		return span, j + n, true
+	}
+	return Inline{}, 0, false
+}
+
+func runLength(s string, i, end int, c byte) int {
+	n := 0
+	for i+n < end && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isAutolink(s string) bool {
+	return (strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "mailto:")) &&
+		!strings.ContainsAny(s, " <>\n")
+}
@@ -0,0 +1,188 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	src := "# Title\n\nIntro line one\nline two.\n\n## Section ##\n\n- one\n- two\n  continued\n  - nested\n- three\n\n" +
+		"1. first\n2. second\n\n> quoted\n> text\n\n```go\nfmt.Println(\"hi\")\n```\n\n---\n\n" +
+		"| Name | Value |\n|:-----|------:|\n| a | 1 |\n| b \\| c | `x|y` |\n\nTrailing paragraph"
+
+	blocks := Parse(src)
+
+	kinds := []BlockKind{Heading, Paragraph, Heading, List, List, Quote, CodeBlock, Rule, Table, Paragraph}
+	if len(blocks) != len(kinds) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(kinds), blocks)
+	}
+	for i, k := range kinds {
+		if blocks[i].Kind != k {
+			t.Errorf("block %d kind = %v, want %v", i, blocks[i].Kind, k)
+		}
+	}
+
+	if blocks[0].Level != 1 || blocks[0].Text != "Title" {
+		t.Errorf("heading = %+v", blocks[0])
+	}
+	if blocks[1].Text != "Intro line one\nline two." {
+		t.Errorf("paragraph text = %q", blocks[1].Text)
+	}
+	if blocks[2].Level != 2 || blocks[2].Text != "Section" {
+		t.Errorf("closed heading = %+v", blocks[2])
+	}
+
+	list := blocks[3]
+	if list.Ordered || len(list.Items) != 3 {
+		t.Fatalf("bullet list = %+v", list)
+	}
+	if got := list.Items[1]; len(got) != 2 || got[0].Text != "two\ncontinued" || got[1].Kind != List {
+		t.Errorf("item with nested list = %+v", got)
+	}
+
+	ordered := blocks[4]
+	if !ordered.Ordered || ordered.Start != 1 || len(ordered.Items) != 2 {
+		t.Errorf("ordered list = %+v", ordered)
+	}
+
+	quote := blocks[5]
+	if len(quote.Children) != 1 || quote.Children[0].Text != "quoted\ntext" {
+		t.Errorf("quote = %+v", quote)
+	}
+
+	code := blocks[6]
+	if code.Lang != "go" || code.Text != `fmt.Println("hi")` {
+		t.Errorf("code block = %+v", code)
+	}
+
+	table := blocks[8]
+	if !reflect.DeepEqual(table.Header, []string{"Name", "Value"}) {
+		t.Errorf("table header = %v", table.Header)
+	}
+	if !reflect.DeepEqual(table.Align, []Align{AlignLeft, AlignRight}) {
+		t.Errorf("table align = %v", table.Align)
+	}
+	if !reflect.DeepEqual(table.Rows, [][]string{{"a", "1"}, {"b | c", "`x|y`"}}) {
+		t.Errorf("table rows = %v", table.Rows)
+	}
+}
+
+func TestParse_NotAHeading(t *testing.T) {
+	blocks := Parse("#hashtag is text\n-not a list")
+	if len(blocks) != 1 || blocks[0].Kind != Paragraph {
+		t.Errorf("Parse() = %+v, want single paragraph", blocks)
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Inline
+	}{
+		{
+			name: "emphasis and strong",
+			src:  "a *b* __c__ d",
+			want: []Inline{
+				{Kind: Text, Text: "a "},
+				{Kind: Emphasis, Children: []Inline{{Kind: Text, Text: "b"}}},
+				{Kind: Text, Text: " "},
+				{Kind: Strong, Children: []Inline{{Kind: Text, Text: "c"}}},
+				{Kind: Text, Text: " d"},
+			},
+		},
+		{
+			name: "nested strong emphasis",
+			src:  "**bold *both***",
+			want: []Inline{
+				{Kind: Strong, Children: []Inline{
+					{Kind: Text, Text: "bold "},
+					{Kind: Emphasis, Children: []Inline{{Kind: Text, Text: "both"}}},
+				}},
+			},
+		},
+		{
+			name: "code span keeps markup",
+			src:  "run `a *b*`",
+			want: []Inline{{Kind: Text, Text: "run "}, {Kind: Code, Text: "a *b*"}},
+		},
+		{
+			name: "link and image",
+			src:  "see [the *docs*](/page/docs \"Docs\") ![logo](logo.png)",
+			want: []Inline{
+				{Kind: Text, Text: "see "},
+				{Kind: Link, URL: "/page/docs", Children: []Inline{
+					{Kind: Text, Text: "the "},
+					{Kind: Emphasis, Children: []Inline{{Kind: Text, Text: "docs"}}},
+				}},
+				{Kind: Text, Text: " "},
+				{Kind: Image, Text: "logo", URL: "logo.png"},
+			},
+		},
+		{
+			name: "autolink",
+			src:  "<https://example.com>",
+			want: []Inline{{Kind: Link, URL: "https://example.com", Children: []Inline{{Kind: Text, Text: "https://example.com"}}}},
+		},
+		{
+			name: "literal markers",
+			src:  `snake_case_name 2 * 3 \*x\* [not a link]`,
+			want: []Inline{{Kind: Text, Text: "snake_case_name 2 * 3 *x* [not a link]"}},
+		},
+		{
+			name: "soft and hard breaks",
+			src:  "one\ntwo  \nthree",
+			want: []Inline{{Kind: Text, Text: "one two"}, {Kind: LineBreak}, {Kind: Text, Text: "three"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseInline(tt.src)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseInline(%q) =\n%+v\nwant\n%+v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := PlainText(ParseInline("**Go** is [a *language*](/page/go) with `code`"))
+	want := "Go is a language with code"
+	if got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	upper := func(dest string) string { return strings.ToUpper(dest) }
+
+	tests := []struct {
+		name, src, want string
+	}{
+		{"link", "See [Go](/page/go) now.", "See [Go](/PAGE/GO) now."},
+		{"title kept", `[a](x "Title")`, `[a](X "Title")`},
+		{"image", "![alt](img.png)", "![alt](IMG.PNG)"},
+		{"balanced parens", "[Go](/page/go_(lang)) x", "[Go](/PAGE/GO_(LANG)) x"},
+		{"angle brackets", "[a](<b c>)", "[a](<B C>)"},
+		{"several", "[a](x) and [b](y)", "[a](X) and [b](Y)"},
+		{"code span", "`[a](x)` and [b](y)", "`[a](x)` and [b](Y)"},
+		{"not a link", "[a] (x) and a](", "[a] (x) and a]("},
+		{"fenced code", "```\n[a](x)\n```\n[b](y)\n", "```\n[a](x)\n```\n[b](Y)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RewriteLinks(tt.src, upper); got != tt.want {
+				t.Errorf("RewriteLinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("wraps unsafe destinations", func(t *testing.T) {
+		got := RewriteLinks("[a](x)", func(string) string { return "a b(" })
+		if want := "[a](<a b(>)"; got != want {
+			t.Errorf("RewriteLinks() = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package markdown
+
+import "strings"
+
+// RewriteLinks returns src with the destination of every link and image
+// replaced by fn(destination). Everything else, including code blocks
+// and code spans, is left untouched.
+func RewriteLinks(src string, fn func(dest string) string) string {
+	var b strings.Builder
+	fence := ""
+	for _, line := range strings.SplitAfter(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			b.WriteString(line)
+		case fenceMarker(trimmed) != "":
+			fence = fenceMarker(trimmed)
+			b.WriteString(line)
+		default:
+			b.WriteString(rewriteLine(line, fn))
+		}
+	}
+	return b.String()
+}
+
+// rewriteLine rewrites the link destinations of a single line.
+func rewriteLine(line string, fn func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '`':
+			n := runLength(line, i, len(line), '`')
+			if close := strings.Index(line[i+n:], strings.Repeat("`", n)); close >= 0 {
+				i += n + close + n - 1
+			} else {
+				i += n - 1
+			}
+		case ']':
+			if i+1 >= len(line) || line[i+1] != '(' {
+				continue
+			}
+			start, end, ok := destination(line, i+2)
+			if !ok {
+				continue
+			}
+			dest := strings.TrimSuffix(strings.TrimPrefix(line[start:end], "<"), ">")
+			repl := fn(dest)
+			if strings.ContainsAny(repl, " \t") || strings.Count(repl, "(") != strings.Count(repl, ")") {
+				repl = "<" + repl + ">"
+			}
+			b.WriteString(line[last:start])
+			b.WriteString(repl)
+			last = end
+			i = end - 1
+		}
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// destination returns the bounds of the link destination that starts
+// at i, just after "](", without surrounding spaces or a title.
+func destination(line string, i int) (start, end int, ok bool) {
+	for i < len(line) && line[i] == ' ' {
+		i++
+	}
+	start = i
+	if i < len(line) && line[i] == '<' {
+		close := strings.IndexByte(line[i:], '>')
+		if close < 0 {
+			return 0, 0, false
+		}
+		return start, i + close + 1, true
+	}
+	parens := 0
+	for ; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '(':
+			parens++
+		case ')':
+			if parens == 0 {
+				return start, i, i > start
+			}
+			parens--
+		case ' ', '\n', '\t':
+			return start, i, i > start
+		}
+	}
+	return 0, 0, false
+}
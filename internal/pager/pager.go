@@ -0,0 +1,78 @@
+// Package pager pipes long command output through an interactive pager
+// such as less.
+package pager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// DefaultCommand is used when $PAGER is not set.
+const DefaultCommand = "less -R"
+
+// ErrNotStarted is returned by Run when the pager could not be started.
+// Nothing has been written in that case, so the caller can print the
+// output itself.
+var ErrNotStarted = errors.New("pager not started")
+
+// Command returns the pager command line to use: $PAGER if set,
+// otherwise DefaultCommand.
+func Command() string {
+	if p := strings.TrimSpace(os.Getenv("PAGER")); p != "" {
+		return p
+	}
+	return DefaultCommand
+}
+
+// Run starts the pager described by cmdline with the given stdout and
+// stderr and writes s to its input. Quitting the pager before reading
+// all of s is not an error.
+func Run(cmdline, s string, stdout, stderr io.Writer) error {
+	args := strings.Fields(cmdline)
+	if len(args) == 0 {
+		return fmt.Errorf("%w: empty command", ErrNotStarted)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+	// Like git: quit if the output fits on one screen, pass colors
+	// through and do not clear the screen on exit.
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if os.Getenv("LV") == "" {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotStarted, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotStarted, err)
+	}
+
+	_, werr := io.WriteString(in, s)
+	in.Close()
+	// The pager's exit status is not meaningful to us: it reflects how
+	// the user left it, not whether our command succeeded.
+	cmd.Wait()
+
+	if werr != nil && !brokenPipe(werr) {
+		return fmt.Errorf("writing to pager: %w", werr)
+	}
+	return nil
+}
+
+// brokenPipe reports whether err was caused by the pager exiting before
+// reading all of its input.
+func brokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed)
+}
@@ -0,0 +1,63 @@
+package pager
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	for _, name := range []string{"cat", "head"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s not available: %v", name, err)
+		}
+	}
+
+	t.Run("passes output through", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := Run("cat", "hello\nworld\n", &out, &out); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if out.String() != "hello\nworld\n" {
+			t.Errorf("pager output = %q", out.String())
+		}
+	})
+
+	t.Run("early quit is not an error", func(t *testing.T) {
+		var out bytes.Buffer
+		big := strings.Repeat("line of output\n", 200000)
+		if err := Run("head -n 1", big, &out, &out); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if out.String() != "line of output\n" {
+			t.Errorf("pager output = %q", out.String())
+		}
+	})
+
+	t.Run("missing pager", func(t *testing.T) {
+		var out bytes.Buffer
+		err := Run("grokir-no-such-pager", "x", &out, &out)
+		if !errors.Is(err, ErrNotStarted) {
+			t.Errorf("Run() error = %v, want ErrNotStarted", err)
+		}
+	})
+
+	t.Run("empty command", func(t *testing.T) {
+		if err := Run("  ", "x", nil, nil); !errors.Is(err, ErrNotStarted) {
+			t.Errorf("Run() error = %v, want ErrNotStarted", err)
+		}
+	})
+}
+
+func TestCommand(t *testing.T) {
+	t.Setenv("PAGER", "")
+	if got := Command(); got != DefaultCommand {
+		t.Errorf("Command() = %q, want %q", got, DefaultCommand)
+	}
+	t.Setenv("PAGER", "more")
+	if got := Command(); got != "more" {
+		t.Errorf("Command() = %q, want %q", got, "more")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package term
+
+import "os"
+
+func size(f *os.File) (width, height int, ok bool) {
+	return 0, 0, false
+}
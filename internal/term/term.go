@@ -0,0 +1,36 @@
+// Package term detects terminal capabilities of the standard streams.
+package term
+
+import (
+	"os"
+	"strconv"
+)
+
+// IsTerminal reports whether f is connected to a terminal.
+func IsTerminal(f *os.File) bool {
+	if _, _, ok := size(f); ok {
+		return true
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Size returns the width and height of the terminal attached to f,
+// falling back to $COLUMNS and $LINES. Unknown dimensions are zero.
+func Size(f *os.File) (width, height int) {
+	if w, h, ok := size(f); ok && w > 0 {
+		return w, h
+	}
+	width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	height, _ = strconv.Atoi(os.Getenv("LINES"))
+	return width, height
+}
+
+// Color reports whether ANSI colors should be written to f: it must be
+// a terminal, NO_COLOR must be unset and TERM must not be "dumb".
+func Color(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(f)
+}